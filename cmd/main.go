@@ -1,73 +1,61 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
-	"net/url"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 	"time"
 
 	http "myserver/internals/http"
+	"myserver/internals/openapi"
 	internals "myserver/internals/server"
 	types "myserver/internals/type"
+	"myserver/static"
 )
 
 const port = 8080
-const staticDir = "/home/mohe/Documents/github/my-server/static"
 
-// Serve root page
-func send(res *http.ResponseWriter, req *http.Request) *types.RouteError {
-	res.Status = types.OK
-	return res.SendFile(filepath.Join(staticDir, "indx.html"))
-}
-
-// Serve static files
-func serveStatic(res *http.ResponseWriter, req *http.Request) *types.RouteError {
-	path := req.RequestLine.Path
-	if path == "/" || path == "." {
-		path = "/index.html"
-	}
-
-	fullPath := filepath.Join(staticDir, path)
-	res.Status = types.OK
-
-	return res.SendFile(fullPath)
+// LoginForm is the form posted to /login.
+type LoginForm struct {
+	Username string `form:"username" json:"username" validate:"required"`
+	Password string `form:"password" json:"password" validate:"required"`
 }
 
 // Handle login with simple logic
 func handleLogin(res *http.ResponseWriter, req *http.Request) *types.RouteError {
+	form, routeErr := http.Bind[LoginForm](req)
+	if routeErr != nil {
+		return routeErr
+	}
 
-	values, err := url.ParseQuery(string(req.Body))
-	if err != nil {
-		res.Status = types.BadRequest
-		return &types.RouteError{Code: types.BadRequest, Message: "Invalid form data"}
+	if form.Username == "admin" && form.Password == "1234" {
+		return res.SendJSON(LoginResult{Status: "success", Message: "Login successful"}, types.OK)
 	}
 
-	username := values.Get("username")
-	password := values.Get("password")
+	return res.SendJSON(LoginResult{Status: "error", Message: "Invalid credentials"}, types.Unauthorized)
+}
 
-	if username == "admin" && password == "1234" {
-		return res.SendJSON(map[string]string{
-			"status":  "success",
-			"message": "Login successful",
-		}, types.OK)
-	}
+type LoginResult struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
 
-	return res.SendJSON(map[string]string{
-		"status":  "error",
-		"message": "Invalid credentials",
-	}, types.Unauthorized)
+// SearchParams are the route parameters of /search.
+type SearchParams struct {
+	FirstID  int    `param:"firstID"`
+	SecondID string `param:"secondID"`
 }
 
 // Example route with params
-func Search(res *http.ResponseWriter, req *http.Request) *types.RouteError {
-	firstID, _ := req.Params.Get("firstID")
-	secondID, _ := req.Params.Get("secondID")
-	fmt.Println("FirstID:", firstID, "SecondID:", secondID)
-	return nil
+func Search(ctx context.Context, in SearchParams) (SearchResult, error) {
+	return SearchResult{FirstID: in.FirstID, SecondID: in.SecondID}, nil
+}
+
+type SearchResult struct {
+	FirstID  int    `json:"firstID"`
+	SecondID string `json:"secondID"`
 }
 
 func LoggingMiddleware(next internals.Handler) internals.Handler {
@@ -78,34 +66,71 @@ func LoggingMiddleware(next internals.Handler) internals.Handler {
 }
 
 // Info endpoint
-func handleInfo(res *http.ResponseWriter, req *http.Request) *types.RouteError {
-	data := map[string]any{
-		"status":  "success",
-		"message": "Server is running",
-		"time":    time.Now().Format(time.RFC3339),
+func handleInfo(ctx context.Context, _ struct{}) (Info, error) {
+	return Info{
+		Status:  "success",
+		Message: "Server is running",
+		Time:    time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+type Info struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Time    string `json:"time"`
+}
+
+func registerRoutes(server *internals.Server) {
+	server.Use(LoggingMiddleware)
+
+	// Routes with middleware
+	server.Handle(types.GET, "/{file...}", internals.FileServerFS(static.Files, internals.FileOptions{Index: "indx.html"}))
+	server.Handle(types.GET, "/search/{firstID:int}/ds/{secondID:[a-z0-9-]+}", internals.JSON(Search)).
+		Name("search").
+		Summary("Echo the search parameters").
+		Param("firstID", "Numeric ID").
+		Param("secondID", "Slug made of lower-case letters, digits and dashes").
+		Response(types.OK, SearchResult{})
+	server.Handle(types.POST, "/login", handleLogin).
+		Summary("Log in with a username and password, as a form or JSON").
+		Request(LoginForm{}).
+		Response(types.OK, LoginResult{}).
+		Response(types.Unauthorized, LoginResult{})
+
+	// API routes share the /api prefix
+	api := server.Group("/api")
+	api.Handle(types.GET, "/info", internals.JSON(handleInfo)).
+		Summary("Server status").
+		Response(types.OK, Info{})
+
+	// Route table, for checking a deployment. It exposes every route, so
+	// it is only served when asked for.
+	if os.Getenv("DEBUG_ROUTES") == "1" {
+		server.Handle(types.GET, "/debug/routes", server.RoutesHandler())
 	}
-	return res.SendJSON(data, types.OK)
+	server.ServeOpenAPI("/openapi.json", openapi.Info{Title: "myserver", Version: "1.0.0"})
 }
 
 // Main function
+//
+//	go run ./cmd                  start the server
+//	DEBUG_ROUTES=1 go run ./cmd   also serve the route table at /debug/routes
+//	go run ./cmd routes           print the route table and exit
 func main() {
-	server, err := internals.ServeHTTP(port)
-	if err != nil {
+	server := internals.NewServer(10 * time.Second)
+	registerRoutes(server)
+
+	if len(os.Args) > 1 && os.Args[1] == "routes" {
+		printRoutes(os.Stdout, server.Routes())
+		return
+	}
+
+	if err := server.Listen(port); err != nil {
 		log.Fatal(err)
 	}
 	defer server.Close()
 	log.Println("Server running on:", port)
 
-	server.Use(LoggingMiddleware)
-
-	// Routes with middleware
-	server.Handle(types.GET, "/", send)
-	server.Handle(types.GET, "/style.css", serveStatic)
-	server.Handle(types.GET, "/script.js", serveStatic)
-	server.Handle(types.GET, "/search/{firstID}/ds/{secondID}", Search)
-	server.Handle(types.GET, "/api/info", handleInfo)
-	server.Handle(types.POST, "/login", handleLogin)
-
 	// Graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
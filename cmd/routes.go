@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	internals "myserver/internals/server"
+)
+
+// printRoutes writes the route table as aligned columns.
+func printRoutes(out io.Writer, routes []internals.RouteInfo) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tMETHOD\tPATTERN\tNAME\tGROUP\tMIDDLEWARE")
+	for _, r := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			orDash(r.Host), r.Method, r.Pattern, orDash(r.Name), orDash(r.Group), orDash(strings.Join(r.Middlewares, ", ")))
+	}
+	tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,262 @@
+package http
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"time"
+
+	types "myserver/internals/type"
+	url "myserver/internals/utils"
+)
+
+// Bind decodes a request into a new T, which must be a struct, and validates
+// it. The body is decoded according to Content-Type:
+//
+//	application/json                   encoding/json, using the json tags
+//	application/x-www-form-urlencoded  fields tagged form:"name"
+//	multipart/form-data                form fields and files (*multipart.FileHeader)
+//
+// Fields can also be filled from the rest of the request, after the body:
+//
+//	param:"id"         route parameter
+//	query:"page"       query string
+//	header:"X-Token"   request header
+//
+// Finally the validate tags are checked (see Validate). Input that cannot be
+// decoded is answered with 400, input that fails validation with 422; both
+// list the offending fields in RouteError.Fields.
+//
+//	type LoginForm struct {
+//		Username string `form:"username" validate:"required"`
+//		Password string `form:"password" validate:"required,min=4"`
+//	}
+//	form, routeErr := http.Bind[LoginForm](req)
+func Bind[T any](req *Request) (T, *types.RouteError) {
+	var dst T
+	v := reflect.ValueOf(&dst).Elem()
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("http: Bind target must be a struct, got %s", v.Type()))
+	}
+
+	if routeErr := bindBody(req, v); routeErr != nil {
+		return dst, routeErr
+	}
+
+	query := req.URL.Query()
+	var fieldErrs []types.FieldError
+	fieldErrs = bindTag(v, "param", fieldErrs, func(key string) []string {
+		if value, err := req.Params.Get(key); err == nil {
+			return []string{value}
+		}
+		return nil
+	})
+	fieldErrs = bindTag(v, "query", fieldErrs, func(key string) []string { return query[key] })
+	fieldErrs = bindTag(v, "header", fieldErrs, req.Headers.Values)
+	if len(fieldErrs) > 0 {
+		return dst, &types.RouteError{Code: types.BadRequest, Message: "invalid request", Fields: fieldErrs}
+	}
+
+	if fieldErrs := Validate(&dst); len(fieldErrs) > 0 {
+		return dst, &types.RouteError{Code: types.UnprocessableContent, Message: "validation failed", Fields: fieldErrs}
+	}
+	return dst, nil
+}
+
+// bindBody decodes the body into v. Requests without a body are left alone.
+func bindBody(req *Request, v reflect.Value) *types.RouteError {
+	if req.ContentLength == 0 {
+		return nil
+	}
+	contentType, _ := req.Headers.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &types.RouteError{Code: types.BadRequest, Message: "invalid Content-Type"}
+	}
+
+	switch mediaType {
+	case "application/json":
+		err := json.NewDecoder(req.Body).Decode(v.Addr().Interface())
+		if err == nil || errors.Is(err, io.EOF) {
+			return nil
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return &types.RouteError{
+				Code:    types.BadRequest,
+				Message: "invalid request body",
+				Fields:  []types.FieldError{{Field: typeErr.Field, Message: "must be " + typeErr.Type.String()}},
+			}
+		}
+		// errors from reading the body keep their own status, e.g. 413
+		// for a chunked body over MaxBodyBytes
+		if status := ErrorStatus(err); status != types.BadRequest {
+			return &types.RouteError{Code: status, Message: "failed to read body"}
+		}
+		return &types.RouteError{Code: types.BadRequest, Message: "invalid JSON body: " + err.Error()}
+
+	case "application/x-www-form-urlencoded":
+		data, err := req.ReadBody()
+		if err != nil {
+			return &types.RouteError{Code: ErrorStatus(err), Message: "failed to read body"}
+		}
+		values := url.ParseQuery(string(data))
+		return fieldsError(bindTag(v, "form", nil, func(key string) []string { return values[key] }))
+
+	case "multipart/form-data":
+		if params["boundary"] == "" {
+			return &types.RouteError{Code: types.BadRequest, Message: "missing multipart boundary"}
+		}
+		// the body is already bounded by MaxBodyBytes, so this keeps most
+		// forms in memory; files that still spill to disk are removed by
+		// Finish, once the handler is done with them
+		form, err := multipart.NewReader(req.Body, params["boundary"]).ReadForm(req.limits.MaxBodyBytes)
+		if err != nil {
+			if status := ErrorStatus(err); status != types.BadRequest {
+				return &types.RouteError{Code: status, Message: "failed to read body"}
+			}
+			return &types.RouteError{Code: types.BadRequest, Message: "invalid multipart body"}
+		}
+		req.cleanups = append(req.cleanups, form.RemoveAll)
+		bindFiles(v, form.File)
+		return fieldsError(bindTag(v, "form", nil, func(key string) []string { return form.Value[key] }))
+
+	default:
+		return &types.RouteError{Code: types.UnsupportedMediaType, Message: "unsupported Content-Type " + mediaType}
+	}
+}
+
+func fieldsError(fieldErrs []types.FieldError) *types.RouteError {
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	return &types.RouteError{Code: types.BadRequest, Message: "invalid request", Fields: fieldErrs}
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	fileHeaderType      = reflect.TypeFor[*multipart.FileHeader]()
+	timeType            = reflect.TypeFor[time.Time]()
+)
+
+// bindTag sets every field of v tagged with tag from the values lookup
+// returns for the tag's name. Embedded structs are walked as well. Conversion
+// failures are appended to fieldErrs.
+func bindTag(v reflect.Value, tag string, fieldErrs []types.FieldError, lookup func(key string) []string) []types.FieldError {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fieldErrs = bindTag(fv, tag, fieldErrs, lookup)
+			continue
+		}
+		key := field.Tag.Get(tag)
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		values := lookup(key)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setValue(fv, values); err != nil {
+			fieldErrs = append(fieldErrs, types.FieldError{Field: key, Message: err.Error()})
+		}
+	}
+	return fieldErrs
+}
+
+// bindFiles sets the fields tagged form:"name" that hold uploaded files, of
+// type *multipart.FileHeader or []*multipart.FileHeader. Embedded structs
+// are walked as well, as in bindTag.
+func bindFiles(v reflect.Value, files map[string][]*multipart.FileHeader) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindFiles(v.Field(i), files)
+			continue
+		}
+		key := field.Tag.Get("form")
+		if key == "" || len(files[key]) == 0 || !field.IsExported() {
+			continue
+		}
+		switch field.Type {
+		case fileHeaderType:
+			v.Field(i).Set(reflect.ValueOf(files[key][0]))
+		case reflect.SliceOf(fileHeaderType):
+			v.Field(i).Set(reflect.ValueOf(files[key]))
+		}
+	}
+}
+
+// setValue converts the textual values into fv: scalars take the first
+// value, slices take them all, pointers are allocated as needed and types
+// implementing encoding.TextUnmarshaler parse themselves.
+func setValue(fv reflect.Value, values []string) error {
+	if fv.Type() == fileHeaderType || fv.Type() == reflect.SliceOf(fileHeaderType) {
+		// files are set by bindFiles
+		return nil
+	}
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setValue(fv.Elem(), values)
+	}
+	if fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	if fv.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	value := values[0]
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid integer", value)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid unsigned integer", value)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid number", value)
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
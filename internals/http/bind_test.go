@@ -0,0 +1,208 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"slices"
+	"strings"
+	"testing"
+
+	types "myserver/internals/type"
+	url "myserver/internals/utils"
+)
+
+// newRequest parses a request carrying body with the given Content-Type.
+func newRequest(t *testing.T, target, contentType, body string) *Request {
+	t.Helper()
+	raw := fmt.Sprintf("POST %s HTTP/1.1\r\nHost: example.com\r\nContent-Type: %s\r\nContent-Length: %d\r\n\r\n%s",
+		target, contentType, len(body), body)
+	req, err := ParseRequest(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ParseRequest: %v", err)
+	}
+	return req
+}
+
+type Page struct {
+	Page int `query:"page"`
+}
+
+type Profile struct {
+	Page
+	ID    int      `param:"id"`
+	Name  string   `json:"name" form:"name" validate:"required,min=2"`
+	Email string   `json:"email" form:"email" validate:"email"`
+	Tags  []string `json:"tags" form:"tag"`
+	Token string   `header:"X-Token"`
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        Profile
+		status      types.StatusCode
+		fields      []string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"name":"ada","email":"ada@example.com","tags":["a","b"]}`,
+			want:        Profile{Page: Page{2}, ID: 7, Name: "ada", Email: "ada@example.com", Tags: []string{"a", "b"}, Token: "t0k"},
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=ada+l&email=ada%40example.com&tag=a&tag=b",
+			want:        Profile{Page: Page{2}, ID: 7, Name: "ada l", Email: "ada@example.com", Tags: []string{"a", "b"}, Token: "t0k"},
+		},
+		{
+			name:        "json with a wrong type",
+			contentType: "application/json",
+			body:        `{"name":5}`,
+			status:      types.BadRequest,
+			fields:      []string{"name"},
+		},
+		{
+			name:        "malformed json",
+			contentType: "application/json",
+			body:        `{"name":`,
+			status:      types.BadRequest,
+		},
+		{
+			name:        "validation",
+			contentType: "application/json",
+			body:        `{"name":"a","email":"nope"}`,
+			status:      types.UnprocessableContent,
+			fields:      []string{"name", "email"},
+		},
+		{
+			name:        "unsupported media type",
+			contentType: "text/csv",
+			body:        "a,b",
+			status:      types.UnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, "/users/7?page=2", tt.contentType, tt.body)
+			req.Headers.Set("X-Token", "t0k")
+			req.Params = url.Params{{Key: "id", Value: "7"}}
+
+			got, routeErr := Bind[Profile](req)
+			if tt.status != 0 {
+				if routeErr == nil || routeErr.Code != tt.status {
+					t.Fatalf("Bind error = %v, want status %d", routeErr, tt.status)
+				}
+				var fields []string
+				for _, f := range routeErr.Fields {
+					fields = append(fields, f.Field)
+				}
+				if !slices.Equal(fields, tt.fields) {
+					t.Errorf("fields = %v, want %v", fields, tt.fields)
+				}
+				return
+			}
+			if routeErr != nil {
+				t.Fatalf("Bind: %v", routeErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Bind = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type Attachment struct {
+	File *multipart.FileHeader `form:"file"`
+}
+
+type Upload struct {
+	Attachment
+	Title  string                  `form:"title"`
+	Extras []*multipart.FileHeader `form:"extra"`
+}
+
+func TestBindMultipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "report")
+	for name, content := range map[string]string{"file": "main", "extra": "first"} {
+		fw, _ := mw.CreateFormFile(name, name+".txt")
+		io.WriteString(fw, content)
+	}
+	fw, _ := mw.CreateFormFile("extra", "second.txt")
+	io.WriteString(fw, "second")
+	mw.Close()
+
+	req := newRequest(t, "/upload", mw.FormDataContentType(), body.String())
+	got, routeErr := Bind[Upload](req)
+	if routeErr != nil {
+		t.Fatalf("Bind: %v", routeErr)
+	}
+	defer req.Finish()
+
+	if got.Title != "report" {
+		t.Errorf("Title = %q", got.Title)
+	}
+	if got.File == nil {
+		t.Fatal("file on the embedded struct was not bound")
+	}
+	f, err := got.File.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != "main" {
+		t.Errorf("file content = %q", data)
+	}
+	if len(got.Extras) != 2 || got.Extras[1].Filename != "second.txt" {
+		t.Errorf("Extras = %+v", got.Extras)
+	}
+	if len(req.cleanups) != 1 {
+		t.Errorf("Bind registered %d cleanups, want 1 for the form", len(req.cleanups))
+	}
+}
+
+func TestBindBodyErrors(t *testing.T) {
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	mw.WriteField("name", strings.Repeat("a", 64))
+	mw.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      types.StatusCode
+		message     string
+	}{
+		{"JSON over the limit", "application/json", `{"name":"` + strings.Repeat("a", 64) + `"}`, types.ContentTooLarge, "failed to read body"},
+		{"form over the limit", "application/x-www-form-urlencoded", "name=" + strings.Repeat("a", 64), types.ContentTooLarge, "failed to read body"},
+		{"multipart over the limit", mw.FormDataContentType(), multipartBody.String(), types.ContentTooLarge, "failed to read body"},
+		{"malformed JSON", "application/json", `{"name":`, types.BadRequest, "invalid JSON body"},
+		{"bad multipart", mw.FormDataContentType(), "not a multipart body", types.BadRequest, "invalid multipart body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Type: " + tt.contentType +
+				"\r\nTransfer-Encoding: chunked\r\n\r\n" + fmt.Sprintf("%x\r\n%s\r\n0\r\n\r\n", len(tt.body), tt.body)
+			req, err := ParseRequestWithLimits(strings.NewReader(raw), Limits{MaxBodyBytes: 32})
+			if err != nil {
+				t.Fatalf("ParseRequest: %v", err)
+			}
+			defer req.Finish()
+
+			_, routeErr := Bind[Profile](req)
+			if routeErr == nil {
+				t.Fatal("Bind succeeded")
+			}
+			if routeErr.Code != tt.status || !strings.HasPrefix(routeErr.Message, tt.message) {
+				t.Errorf("Bind = %d %q, want %d %q", routeErr.Code, routeErr.Message, tt.status, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	types "myserver/internals/type"
+)
+
+// body is the io.ReadCloser behind Request.Body. It decodes the framing
+// (Content-Length or chunked) on the fly, reading from the connection only
+// when the handler asks for more data.
+type body struct {
+	req       *Request
+	src       *Reader
+	remaining int64 // bytes left for a Content-Length body
+	read      int64 // decoded bytes handed out so far
+	chunkLeft int
+	chunkEnd  bool
+	closed    bool
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, ErrBodyReadAfterClose
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	req := b.req
+	for {
+		data := b.src.data()
+		switch req.status {
+		case types.StateBody:
+			if len(data) == 0 {
+				if err := b.fill(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			n := copy(p[:min(int64(len(p)), b.remaining)], data)
+			b.src.consume(n)
+			b.remaining -= int64(n)
+			b.read += int64(n)
+			if b.remaining == 0 {
+				req.status = types.StateDone
+			}
+			return n, nil
+		case types.StateChunkedBody:
+			consumed, written, err := b.parseChunk(data, p)
+			if err != nil {
+				return 0, err
+			}
+			b.src.consume(consumed)
+			if written > 0 {
+				return written, nil
+			}
+			if consumed == 0 {
+				if err := b.fill(); err != nil {
+					return 0, err
+				}
+			}
+		case types.StateTrailer:
+			n, err := parseFields(data, req.Trailer, req.limits)
+			if err != nil {
+				return 0, err
+			}
+			if n == 0 {
+				if err := b.fill(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			b.src.consume(n)
+			req.status = types.StateDone
+		default:
+			return 0, io.EOF
+		}
+	}
+}
+
+// Close discards whatever the handler left unread so the next request on a
+// keep-alive connection starts at a message boundary.
+func (b *body) Close() error {
+	if b.closed {
+		return nil
+	}
+	_, err := io.Copy(io.Discard, b)
+	b.closed = true
+	return err
+}
+
+// fill reads more bytes for the body; running out of input before the body is
+// complete is reported as io.ErrUnexpectedEOF.
+func (b *body) fill() error {
+	err := b.src.fill()
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// parseChunk decodes one step of a chunked body into p:
+//
+//	chunk-size [ ; chunk-ext ] CRLF
+//	chunk-data CRLF
+//	...
+//	0 CRLF
+//
+// It returns how many input bytes were consumed and how many body bytes were
+// written to p. Chunk extensions are ignored. Once the last chunk is read the
+// parser moves on to the trailer section.
+func (b *body) parseChunk(data, p []byte) (int, int, error) {
+	// inside chunk-data
+	if b.chunkLeft > 0 {
+		n := copy(p, data[:min(b.chunkLeft, len(data))])
+		b.chunkLeft -= n
+		b.read += int64(n)
+		return n, n, nil
+	}
+	// CRLF closing chunk-data
+	if b.chunkEnd {
+		if len(data) < len(SEPARATOR) {
+			return 0, 0, nil
+		}
+		if !bytes.HasPrefix(data, []byte(SEPARATOR)) {
+			return 0, 0, fmt.Errorf("%w: missing CRLF after chunk data", ErrInvalidChunk)
+		}
+		b.chunkEnd = false
+		return len(SEPARATOR), 0, nil
+	}
+	// chunk-size line
+	idx := bytes.Index(data, []byte(SEPARATOR))
+	if idx == -1 {
+		if len(data) > maxChunkLineLength {
+			return 0, 0, fmt.Errorf("%w: chunk size line too long", ErrInvalidChunk)
+		}
+		return 0, 0, nil
+	}
+	line := data[:idx]
+	if ext := bytes.IndexByte(line, ';'); ext != -1 {
+		line = line[:ext]
+	}
+	size, err := strconv.ParseInt(string(bytes.TrimSpace(line)), 16, 64)
+	if err != nil || size < 0 {
+		return 0, 0, fmt.Errorf("%w: bad chunk size %q", ErrInvalidChunk, data[:idx])
+	}
+	if size == 0 {
+		b.req.status = types.StateTrailer
+		return idx + len(SEPARATOR), 0, nil
+	}
+	// compared this way round so a size near MaxInt64 cannot overflow
+	if size > b.req.limits.MaxBodyBytes-b.read {
+		return 0, 0, ErrRequestTooLarge
+	}
+	b.chunkLeft = int(size)
+	b.chunkEnd = true
+	return idx + len(SEPARATOR), 0, nil
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func chunkedRequest(body string) string {
+	return "POST /upload HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n" + body
+}
+
+func TestChunkedBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		limits  Limits
+		want    string
+		trailer map[string]string
+		err     error
+	}{
+		{
+			name: "single chunk",
+			body: "5\r\nhello\r\n0\r\n\r\n",
+			want: "hello",
+		},
+		{
+			name: "several chunks",
+			body: "5\r\nhello\r\n1\r\n \r\n5\r\nworld\r\n0\r\n\r\n",
+			want: "hello world",
+		},
+		{
+			name: "hex sizes in either case",
+			body: "a\r\n0123456789\r\nA\r\n0123456789\r\n0\r\n\r\n",
+			want: "01234567890123456789",
+		},
+		{
+			name: "extensions are ignored",
+			body: "5;name=value\r\nhello\r\n0;last\r\n\r\n",
+			want: "hello",
+		},
+		{
+			name:    "trailer fields",
+			body:    "5\r\nhello\r\n0\r\nExpires: never\r\nx-checksum: abc\r\n\r\n",
+			want:    "hello",
+			trailer: map[string]string{"Expires": "never", "X-Checksum": "abc"},
+		},
+		{
+			name: "empty body",
+			body: "0\r\n\r\n",
+			want: "",
+		},
+		{
+			name: "size is not hex",
+			body: "zz\r\nhello\r\n0\r\n\r\n",
+			err:  ErrInvalidChunk,
+		},
+		{
+			name: "negative size",
+			body: "-5\r\nhello\r\n0\r\n\r\n",
+			err:  ErrInvalidChunk,
+		},
+		{
+			name: "empty size line",
+			body: "\r\nhello\r\n0\r\n\r\n",
+			err:  ErrInvalidChunk,
+		},
+		{
+			name: "size overflows int64",
+			body: "fffffffffffffffff\r\nhello\r\n0\r\n\r\n",
+			err:  ErrInvalidChunk,
+		},
+		{
+			name: "data longer than its size",
+			body: "3\r\nhello\r\n0\r\n\r\n",
+			err:  ErrInvalidChunk,
+		},
+		{
+			name: "size line too long",
+			body: strings.Repeat("0", maxChunkLineLength+1),
+			err:  ErrInvalidChunk,
+		},
+		{
+			name:   "over the body limit",
+			body:   "5\r\nhello\r\n5\r\nworld\r\n0\r\n\r\n",
+			limits: Limits{MaxBodyBytes: 8},
+			err:    ErrRequestTooLarge,
+		},
+		{
+			name:   "size near MaxInt64 past the limit",
+			body:   "1\r\nA\r\n7fffffffffffffff\r\n" + strings.Repeat("B", 5000) + "\r\n0\r\n\r\n",
+			limits: Limits{MaxBodyBytes: 100},
+			err:    ErrRequestTooLarge,
+		},
+		{
+			name: "invalid trailer",
+			body: "5\r\nhello\r\n0\r\nno colon\r\n\r\n",
+			err:  ErrInvalidHeader,
+		},
+		{
+			name: "connection closed mid chunk",
+			body: "5\r\nhel",
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "connection closed before the last chunk",
+			body: "5\r\nhello\r\n",
+			err:  io.ErrUnexpectedEOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// one byte at a time, so every state has to resume from a
+			// partial buffer
+			input := iotest.OneByteReader(strings.NewReader(chunkedRequest(tt.body)))
+			req, err := ParseRequestWithLimits(input, tt.limits)
+			if err != nil {
+				t.Fatalf("ParseRequest: %v", err)
+			}
+			if req.ContentLength != -1 {
+				t.Errorf("ContentLength = %d, want -1", req.ContentLength)
+			}
+			got, err := io.ReadAll(req.Body)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ReadAll error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadAll: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			for key, want := range tt.trailer {
+				if value, _ := req.Trailer.Get(key); value != want {
+					t.Errorf("trailer %s = %q, want %q", key, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestContentLengthBody(t *testing.T) {
+	input := "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Length: 5\r\n\r\nhello"
+	req, err := ParseRequest(iotest.OneByteReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("ParseRequest: %v", err)
+	}
+	got, err := io.ReadAll(req.Body)
+	if err != nil || string(got) != "hello" {
+		t.Fatalf("body = %q, %v; want %q", got, err, "hello")
+	}
+}
+
+func TestTransferEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers string
+		err     error
+	}{
+		{"chunked", "Transfer-Encoding: chunked\r\n", nil},
+		{"chunked in any case", "Transfer-Encoding:  Chunked \r\n", nil},
+		{"chunked after another coding", "Transfer-Encoding: gzip, chunked\r\n", ErrUnsupportedTransferCoding},
+		{"codings split over lines", "Transfer-Encoding: gzip\r\nTransfer-Encoding: chunked\r\n", ErrUnsupportedTransferCoding},
+		{"chunked twice", "Transfer-Encoding: chunked, chunked\r\n", ErrUnsupportedTransferCoding},
+		{"chunked not last", "Transfer-Encoding: chunked, gzip\r\n", ErrUnsupportedTransferCoding},
+		{"chunked with a length", "Transfer-Encoding: chunked\r\nContent-Length: 5\r\n", ErrInvalidHeader},
+		{"length before chunked", "Content-Length: 5\r\nTransfer-Encoding: chunked\r\n", ErrInvalidHeader},
+		{"conflicting lengths", "Content-Length: 5\r\nContent-Length: 6\r\n", ErrInvalidHeader},
+		{"negative length", "Content-Length: -1\r\n", ErrInvalidHeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "POST / HTTP/1.1\r\nHost: example.com\r\n" + tt.headers + "\r\n0\r\n\r\n"
+			_, err := ParseRequest(strings.NewReader(input))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ParseRequest error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBodyReadAfterClose(t *testing.T) {
+	req, err := ParseRequest(strings.NewReader(chunkedRequest("5\r\nhello\r\n0\r\n\r\n")))
+	if err != nil {
+		t.Fatalf("ParseRequest: %v", err)
+	}
+	if err := req.Body.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := req.Body.Read(make([]byte, 1)); !errors.Is(err, ErrBodyReadAfterClose) {
+		t.Errorf("Read after Close = %v, want %v", err, ErrBodyReadAfterClose)
+	}
+}
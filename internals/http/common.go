@@ -5,14 +5,29 @@ import "errors"
 var (
 	SEPARATOR         = "\r\n"
 	DefaultBufferSize = 4096
+	// maxChunkLineLength bounds a chunk-size line including its extensions.
+	maxChunkLineLength = 4096
+	// DefaultMaxBodySize caps how many body bytes a single request may carry.
+	DefaultMaxBodySize int64 = 10 << 20
+	// TimeFormat is the HTTP-date format (RFC 9110 5.6.7) used for Date and
+	// Last-Modified; times must be in UTC.
+	TimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
 
 	ErrInvalidRequestLine = errors.New("invalid request line")
 	ErrRequestTooLarge    = errors.New("request too large")
+	ErrRequestLineTooLong = errors.New("request line too long")
+	ErrHeaderTooLarge     = errors.New("request header fields too large")
 	ErrInvalidHeader      = errors.New("invalid Headers")
 	ErrInvalidHeaderName  = errors.New("invalid header name: contains whitespace or empty")
 	ErrUnknownStatusCode  = errors.New("unknown status code")
 	ErrMethodNotFound     = errors.New("method not found")
 	ErrPathNotFound       = errors.New("path not found")
+	ErrMissingHost        = errors.New("missing Host header")
+
+	// BODY
+	ErrInvalidChunk              = errors.New("invalid chunk")
+	ErrUnsupportedTransferCoding = errors.New("unsupported transfer coding")
+	ErrBodyReadAfterClose        = errors.New("read on closed request body")
 
 	// HEADER
 	ErrKeyNotFound = errors.New("key not found in header")
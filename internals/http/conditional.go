@@ -0,0 +1,107 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	types "myserver/internals/type"
+)
+
+// ETag returns a strong entity tag for content identified by its
+// modification time and size, the way static files are tagged.
+func ETag(modtime time.Time, size int64) string {
+	return fmt.Sprintf(`"%x-%x"`, modtime.UnixNano(), size)
+}
+
+// ParseTime parses an HTTP-date in any of the three formats RFC 9110 5.6.7
+// requires recipients to accept.
+func ParseTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range []string{TimeFormat, time.RFC850, time.ANSIC} {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// checkPreconditions evaluates the conditional headers of req against the
+// validators of the selected representation, in the order of RFC 9110
+// 13.2.2. It returns 0 when the request should be served normally, or
+// NotModified or PreconditionFailed. A zero modtime or an empty etag means
+// that validator is unknown.
+func checkPreconditions(req *Request, etag string, modtime time.Time) types.StatusCode {
+	if req == nil {
+		return 0
+	}
+	modtime = modtime.Truncate(time.Second)
+
+	// 1. If-Match, else 2. If-Unmodified-Since
+	if ifMatch := req.Headers.Values("If-Match"); len(ifMatch) > 0 {
+		if !etagMatches(ifMatch, etag, false) {
+			return types.PreconditionFailed
+		}
+	} else if since, ok := headerTime(req, "If-Unmodified-Since"); ok && !modtime.IsZero() {
+		if modtime.After(since) {
+			return types.PreconditionFailed
+		}
+	}
+
+	method := req.RequestLine.Method
+	safe := method == types.GET || method == types.HEAD
+	// 3. If-None-Match, else 4. If-Modified-Since (GET and HEAD only)
+	if ifNoneMatch := req.Headers.Values("If-None-Match"); len(ifNoneMatch) > 0 {
+		if etagMatches(ifNoneMatch, etag, true) {
+			if safe {
+				return types.NotModified
+			}
+			return types.PreconditionFailed
+		}
+	} else if since, ok := headerTime(req, "If-Modified-Since"); ok && safe && !modtime.IsZero() {
+		if !modtime.After(since) {
+			return types.NotModified
+		}
+	}
+	return 0
+}
+
+// etagMatches reports whether etag is in the entity-tag lists of a
+// conditional header. "*" matches any current representation, even one
+// without an entity tag (RFC 9110 13.1.1); otherwise an empty etag matches
+// nothing. Weak comparison ignores the W/ prefix; strong comparison never
+// matches a weak tag (RFC 9110 8.8.3.2).
+func etagMatches(lists []string, etag string, weak bool) bool {
+	for _, list := range lists {
+		for tag := range strings.SplitSeq(list, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" {
+				return true
+			}
+			if etag == "" {
+				continue
+			}
+			if weak {
+				if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+					return true
+				}
+			} else if !strings.HasPrefix(tag, "W/") && !strings.HasPrefix(etag, "W/") && tag == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headerTime parses the HTTP-date in the named header. Invalid dates are
+// ignored, as RFC 9110 requires for If-Modified-Since and
+// If-Unmodified-Since.
+func headerTime(req *Request, name string) (time.Time, bool) {
+	value, err := req.Headers.Get(name)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := ParseTime(value)
+	return t, err == nil
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	types "myserver/internals/type"
+)
+
+// headRequest parses a request without a body; headers are "Name: value"
+// lines.
+func headRequest(t *testing.T, method types.Method, headers ...string) *Request {
+	t.Helper()
+	raw := string(method) + " /file HTTP/1.1\r\nHost: example.com\r\n"
+	for _, h := range headers {
+		raw += h + "\r\n"
+	}
+	req, err := ParseRequest(strings.NewReader(raw + "\r\n"))
+	if err != nil {
+		t.Fatalf("ParseRequest: %v", err)
+	}
+	return req
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		list string
+		etag string
+		weak bool
+		want bool
+	}{
+		{`"a"`, `"a"`, false, true},
+		{`"a"`, `"b"`, false, false},
+		{`"x", "a"`, `"a"`, false, true},
+		{`W/"a"`, `"a"`, false, false},
+		{`"a"`, `W/"a"`, false, false},
+		{`W/"a"`, `"a"`, true, true},
+		{`"a"`, `W/"a"`, true, true},
+		{`*`, `"a"`, false, true},
+		{`*`, "", false, true},
+		{`*`, "", true, true},
+		{`"a"`, "", false, false},
+		{`""`, "", true, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches([]string{tt.list}, tt.etag, tt.weak); got != tt.want {
+			t.Errorf("etagMatches(%s, %s, weak=%v) = %v, want %v", tt.list, tt.etag, tt.weak, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	modtime := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	const etag = `"v1"`
+	at := func(d time.Duration) string { return modtime.Add(d).Format(TimeFormat) }
+
+	tests := []struct {
+		name    string
+		method  types.Method
+		headers []string
+		// the representation has no entity tag or no modification time
+		noETag, noModtime bool
+		want              types.StatusCode
+	}{
+		{name: "no conditions", method: types.GET},
+		{name: "If-Match hit", method: types.PUT, headers: []string{`If-Match: "v0", "v1"`}},
+		{name: "If-Match miss", method: types.PUT, headers: []string{`If-Match: "v0"`}, want: types.PreconditionFailed},
+		{name: "If-Match weak never matches", method: types.PUT, headers: []string{`If-Match: W/"v1"`}, want: types.PreconditionFailed},
+		{name: "If-Match star", method: types.PUT, headers: []string{`If-Match: *`}},
+		{name: "If-Match star without validator", method: types.PUT, headers: []string{`If-Match: *`}, noETag: true, noModtime: true},
+		{name: "If-Match without validator", method: types.PUT, headers: []string{`If-Match: "v1"`}, noETag: true, want: types.PreconditionFailed},
+		{name: "If-Unmodified-Since later", method: types.PUT, headers: []string{"If-Unmodified-Since: " + at(time.Hour)}},
+		{name: "If-Unmodified-Since same second", method: types.PUT, headers: []string{"If-Unmodified-Since: " + at(0)}},
+		{name: "If-Unmodified-Since earlier", method: types.PUT, headers: []string{"If-Unmodified-Since: " + at(-time.Hour)}, want: types.PreconditionFailed},
+		{name: "If-Match wins over If-Unmodified-Since", method: types.PUT, headers: []string{`If-Match: "v1"`, "If-Unmodified-Since: " + at(-time.Hour)}},
+		{name: "invalid If-Unmodified-Since is ignored", method: types.PUT, headers: []string{"If-Unmodified-Since: yesterday"}},
+		{name: "If-None-Match hit on GET", method: types.GET, headers: []string{`If-None-Match: "v1"`}, want: types.NotModified},
+		{name: "If-None-Match weak hit on HEAD", method: types.HEAD, headers: []string{`If-None-Match: W/"v1"`}, want: types.NotModified},
+		{name: "If-None-Match hit on PUT", method: types.PUT, headers: []string{`If-None-Match: "v1"`}, want: types.PreconditionFailed},
+		{name: "If-None-Match star on PUT", method: types.PUT, headers: []string{`If-None-Match: *`}, want: types.PreconditionFailed},
+		{name: "If-None-Match miss", method: types.GET, headers: []string{`If-None-Match: "v0"`}},
+		{name: "If-None-Match wins over If-Modified-Since", method: types.GET, headers: []string{`If-None-Match: "v0"`, "If-Modified-Since: " + at(time.Hour)}},
+		{name: "If-Modified-Since same second", method: types.GET, headers: []string{"If-Modified-Since: " + at(0)}, want: types.NotModified},
+		{name: "If-Modified-Since earlier", method: types.GET, headers: []string{"If-Modified-Since: " + at(-time.Second)}},
+		{name: "If-Modified-Since RFC 850", method: types.GET, headers: []string{"If-Modified-Since: " + modtime.Format(time.RFC850)}, want: types.NotModified},
+		{name: "If-Modified-Since ANSI C", method: types.GET, headers: []string{"If-Modified-Since: " + modtime.Format(time.ANSIC)}, want: types.NotModified},
+		{name: "If-Modified-Since ignored on POST", method: types.POST, headers: []string{"If-Modified-Since: " + at(time.Hour)}},
+		{name: "If-Modified-Since without modtime", method: types.GET, headers: []string{"If-Modified-Since: " + at(time.Hour)}, noModtime: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etag, modtime := etag, modtime
+			if tt.noETag {
+				etag = ""
+			}
+			if tt.noModtime {
+				modtime = time.Time{}
+			}
+			req := headRequest(t, tt.method, tt.headers...)
+			if got := checkPreconditions(req, etag, modtime); got != tt.want {
+				t.Errorf("checkPreconditions = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendBytesIfMatchStar(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewResponseWriter(&buf, 0)
+	w.SetRequest(headRequest(t, types.GET, "If-Match: *"))
+	if routeErr := w.SendBytes("data.txt", time.Time{}, []byte("hello")); routeErr != nil {
+		t.Fatalf("SendBytes = %v, want the content", routeErr)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 200 OK\r\n") || !strings.HasSuffix(buf.String(), "hello") {
+		t.Errorf("response = %q", buf.String())
+	}
+}
@@ -1,48 +1,143 @@
 package http
 
-type Header map[string]string
+import (
+	"slices"
+	"strings"
+)
+
+// Header maps canonical field names to their values. A field may appear
+// several times (Set-Cookie, Accept, ...), so every name keeps a list.
+// Keys are canonicalized on every access, so lookups are case-insensitive.
+type Header map[string][]string
 
 func NewHeader() *Header {
 	h := make(Header)
 	return &h
 }
 
+// CanonicalKey returns the canonical form of a field name: the first letter
+// and every letter following a hyphen upper-cased, the rest lower-cased
+// ("content-length" -> "Content-Length"). Names containing bytes that are not
+// valid in a field name are returned unchanged.
+func CanonicalKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isTokenChar(key[i]) {
+			return key
+		}
+	}
+	b := []byte(key)
+	upper := true
+	for i, c := range b {
+		switch {
+		case upper && 'a' <= c && c <= 'z':
+			b[i] = c - ('a' - 'A')
+		case !upper && 'A' <= c && c <= 'Z':
+			b[i] = c + ('a' - 'A')
+		}
+		upper = c == '-'
+	}
+	return string(b)
+}
+
+// isTokenChar reports whether c may appear in an RFC 9110 token.
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+	}
+}
+
+// Get returns the first value of key.
 func (h *Header) Get(key string) (string, error) {
-	if value, ok := (*h)[key]; ok {
-		return value, nil
+	if values := (*h)[CanonicalKey(key)]; len(values) > 0 {
+		return values[0], nil
 	}
 	return "", ErrKeyNotFound
 }
 
+// Values returns every value of key in the order they were added.
+func (h *Header) Values(key string) []string {
+	return (*h)[CanonicalKey(key)]
+}
+
+// Has reports whether key is present.
+func (h *Header) Has(key string) bool {
+	_, ok := (*h)[CanonicalKey(key)]
+	return ok
+}
+
+// Set replaces all values of key with value.
 func (h *Header) Set(key, value string) error {
 	if key == "" {
 		return ErrEmptyKey
 	}
-	(*h)[key] = value
+	(*h)[CanonicalKey(key)] = []string{value}
 	return nil
 }
+
+// Add appends value to the values of key.
+func (h *Header) Add(key, value string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	key = CanonicalKey(key)
+	(*h)[key] = append((*h)[key], value)
+	return nil
+}
+
+// Replace sets key to value only if key is already present.
 func (h *Header) Replace(key, value string) error {
 	if key == "" {
 		return ErrEmptyKey
 	}
-	if _, exists := (*h)[key]; exists {
-		(*h)[key] = value
+	key = CanonicalKey(key)
+	if _, exists := (*h)[key]; !exists {
+		return ErrKeyNotFound
 	}
-	return ErrKeyNotFound
+	(*h)[key] = []string{value}
+	return nil
 }
 
+// Keys returns the field names in sorted order.
+func (h Header) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// ForEach calls f once per value, with keys in sorted order so the output is
+// deterministic.
 func (h Header) ForEach(f func(key, value string)) {
-	for k, v := range h {
-		f(k, v)
+	for _, k := range h.Keys() {
+		for _, v := range h[k] {
+			f(k, v)
+		}
 	}
 }
-func (h *Header) Delete(key string) error {
+
+// Del removes every value of key.
+func (h *Header) Del(key string) error {
 	if key == "" {
 		return ErrEmptyKey
 	}
+	key = CanonicalKey(key)
 	if _, exists := (*h)[key]; !exists {
 		return ErrKeyNotFound
 	}
 	delete(*h, key)
 	return nil
 }
+
+// Clone returns a deep copy of h.
+func (h Header) Clone() Header {
+	clone := make(Header, len(h))
+	for k, v := range h {
+		clone[k] = slices.Clone(v)
+	}
+	return clone
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCanonicalKey(t *testing.T) {
+	tests := []struct {
+		key, want string
+	}{
+		{"content-length", "Content-Length"},
+		{"CONTENT-LENGTH", "Content-Length"},
+		{"cOnTeNt-TyPe", "Content-Type"},
+		{"host", "Host"},
+		{"x-request-id", "X-Request-Id"},
+		{"www-authenticate", "Www-Authenticate"},
+		{"x--double", "X--Double"},
+		{"-leading", "-Leading"},
+		{"x_under_score", "X_under_score"},
+		{"etag2", "Etag2"},
+		{"", ""},
+		// names that are not tokens are left alone
+		{"content length", "content length"},
+		{"x-ünicode", "x-ünicode"},
+		{"bad:name", "bad:name"},
+		{"tab\there", "tab\there"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalKey(tt.key); got != tt.want {
+			t.Errorf("CanonicalKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderAddValues(t *testing.T) {
+	h := NewHeader()
+	h.Add("Accept", "text/html")
+	h.Add("accept", "application/json")
+	h.Add("ACCEPT", "*/*")
+	h.Add("Set-Cookie", "a=1")
+
+	if got, want := h.Values("Accept"), []string{"text/html", "application/json", "*/*"}; !slices.Equal(got, want) {
+		t.Errorf("Values(Accept) = %q, want %q", got, want)
+	}
+	if got, err := h.Get("aCCept"); err != nil || got != "text/html" {
+		t.Errorf("Get(aCCept) = %q, %v; want the first value", got, err)
+	}
+	if !h.Has("set-cookie") || h.Has("Cookie") {
+		t.Errorf("Has: set-cookie = %v, Cookie = %v", h.Has("set-cookie"), h.Has("Cookie"))
+	}
+	if got := h.Values("Missing"); got != nil {
+		t.Errorf("Values(Missing) = %q, want nil", got)
+	}
+	if _, err := h.Get("Missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Get(Missing) error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestHeaderSetReplaceDel(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(h *Header) error
+		err  error
+		// values of "Vary" afterwards; nil means absent
+		want []string
+	}{
+		{
+			name: "Set drops earlier values",
+			op:   func(h *Header) error { return h.Set("vary", "Origin") },
+			want: []string{"Origin"},
+		},
+		{
+			name: "Add keeps earlier values",
+			op:   func(h *Header) error { return h.Add("VARY", "Origin") },
+			want: []string{"Accept", "Accept-Encoding", "Origin"},
+		},
+		{
+			name: "Replace an existing key",
+			op:   func(h *Header) error { return h.Replace("vary", "Origin") },
+			want: []string{"Origin"},
+		},
+		{
+			name: "Replace a missing key",
+			op:   func(h *Header) error { return h.Replace("Age", "0") },
+			err:  ErrKeyNotFound,
+			want: []string{"Accept", "Accept-Encoding"},
+		},
+		{
+			name: "Del",
+			op:   func(h *Header) error { return h.Del("vary") },
+		},
+		{
+			name: "Del a missing key",
+			op:   func(h *Header) error { return h.Del("Age") },
+			err:  ErrKeyNotFound,
+			want: []string{"Accept", "Accept-Encoding"},
+		},
+		{
+			name: "Set with an empty key",
+			op:   func(h *Header) error { return h.Set("", "x") },
+			err:  ErrEmptyKey,
+			want: []string{"Accept", "Accept-Encoding"},
+		},
+		{
+			name: "Add with an empty key",
+			op:   func(h *Header) error { return h.Add("", "x") },
+			err:  ErrEmptyKey,
+			want: []string{"Accept", "Accept-Encoding"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHeader()
+			h.Add("Vary", "Accept")
+			h.Add("Vary", "Accept-Encoding")
+			if err := tt.op(h); !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+			if got := h.Values("Vary"); !slices.Equal(got, tt.want) {
+				t.Errorf("Vary = %q, want %q", got, tt.want)
+			}
+			if h.Has("Vary") != (tt.want != nil) {
+				t.Errorf("Has(Vary) = %v", h.Has("Vary"))
+			}
+		})
+	}
+}
+
+func TestHeaderClone(t *testing.T) {
+	h := NewHeader()
+	h.Add("Accept", "text/html")
+	h.Add("Accept", "application/json")
+
+	clone := h.Clone()
+	clone.Add("Accept", "*/*")
+	clone["Accept"][0] = "text/plain"
+	clone.Set("Host", "example.com")
+
+	if got, want := h.Values("Accept"), []string{"text/html", "application/json"}; !slices.Equal(got, want) {
+		t.Errorf("original Accept = %q, want %q", got, want)
+	}
+	if h.Has("Host") {
+		t.Error("original gained the clone's Host")
+	}
+}
+
+func TestHeaderForEach(t *testing.T) {
+	h := NewHeader()
+	h.Add("x-b", "1")
+	h.Add("Content-Type", "text/plain")
+	h.Add("X-B", "2")
+	h.Add("accept", "*/*")
+
+	var got []string
+	h.ForEach(func(key, value string) {
+		got = append(got, key+": "+value)
+	})
+	want := []string{"Accept: */*", "Content-Type: text/plain", "X-B: 1", "X-B: 2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ForEach = %q, want %q", got, want)
+	}
+	if keys := h.Keys(); !slices.Equal(keys, []string{"Accept", "Content-Type", "X-B"}) {
+		t.Errorf("Keys = %q", keys)
+	}
+}
@@ -0,0 +1,38 @@
+package http
+
+// Limits bounds how much of a request the parser is willing to buffer.
+// A zero field means "use the default".
+type Limits struct {
+	// MaxRequestLineBytes caps "METHOD target VERSION" (414 URI Too Long).
+	MaxRequestLineBytes int
+	// MaxHeaderBytes caps the whole header block (431).
+	MaxHeaderBytes int
+	// MaxHeaderCount caps the number of header lines (431).
+	MaxHeaderCount int
+	// MaxBodyBytes caps the decoded body size (413 Content Too Large).
+	MaxBodyBytes int64
+}
+
+var DefaultLimits = Limits{
+	MaxRequestLineBytes: 8 << 10,
+	MaxHeaderBytes:      1 << 20,
+	MaxHeaderCount:      100,
+	MaxBodyBytes:        DefaultMaxBodySize,
+}
+
+// withDefaults fills every unset field from DefaultLimits.
+func (l Limits) withDefaults() Limits {
+	if l.MaxRequestLineBytes <= 0 {
+		l.MaxRequestLineBytes = DefaultLimits.MaxRequestLineBytes
+	}
+	if l.MaxHeaderBytes <= 0 {
+		l.MaxHeaderBytes = DefaultLimits.MaxHeaderBytes
+	}
+	if l.MaxHeaderCount <= 0 {
+		l.MaxHeaderCount = DefaultLimits.MaxHeaderCount
+	}
+	if l.MaxBodyBytes <= 0 {
+		l.MaxBodyBytes = DefaultLimits.MaxBodyBytes
+	}
+	return l
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the offered media type the client prefers according to
+// accept, the value of an Accept header (RFC 9110 12.5.1). Each offer takes
+// the quality of the most specific range matching it ("text/html" over
+// "text/*" over "*/*"); ties go to the earlier offer. An empty accept takes
+// the first offer, and "" means none of them is acceptable.
+func Negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			if s := r.matches(offer); s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// matches returns how specifically r matches mediaType: 2 for an exact
+// match, 1 for "type/*", 0 for "*/*" and -1 when it does not match.
+func (r mediaRange) matches(mediaType string) int {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	switch {
+	case r.typ == "*" && r.subtype == "*":
+		return 0
+	case !strings.EqualFold(r.typ, typ):
+		return -1
+	case r.subtype == "*":
+		return 1
+	case strings.EqualFold(r.subtype, subtype):
+		return 2
+	default:
+		return -1
+	}
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		typ, subtype, ok := strings.Cut(strings.TrimSpace(mediaType), "/")
+		if !ok {
+			continue
+		}
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for param := range strings.SplitSeq(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	types "myserver/internals/type"
+)
+
+var (
+	errInvalidRange     = errors.New("invalid range")
+	errRangeUnsatisfied = errors.New("no range overlaps the content")
+)
+
+// byteRange is one range of a Range header resolved against the content size.
+type byteRange struct {
+	start, length int64
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// parseRange resolves a Range header such as "bytes=0-99,200-,-50" against
+// size (RFC 9110 14.1.2). Ranges past the end are dropped, and if none is
+// left the result is errRangeUnsatisfied. Syntax errors and other units give
+// errInvalidRange, and the header should then be ignored.
+func parseRange(header string, size int64) ([]byteRange, error) {
+	unit, set, ok := strings.Cut(header, "=")
+	if !ok || strings.TrimSpace(unit) != "bytes" {
+		return nil, errInvalidRange
+	}
+	var ranges []byteRange
+	for spec := range strings.SplitSeq(set, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, errInvalidRange
+		}
+		var r byteRange
+		if first == "" {
+			// suffix range: the last n bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errInvalidRange
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			n = min(n, size)
+			r = byteRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errInvalidRange
+			}
+			end := size - 1
+			if last != "" {
+				end, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, errInvalidRange
+				}
+				end = min(end, size-1)
+			}
+			if start >= size {
+				continue
+			}
+			r = byteRange{start: start, length: end - start + 1}
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, errRangeUnsatisfied
+	}
+	return ranges, nil
+}
+
+// rangesWorthServing guards against requests for many small or overlapping
+// ranges: when they add up to more than the content itself, sending the
+// whole content is cheaper for everyone.
+func rangesWorthServing(ranges []byteRange, size int64) bool {
+	var total int64
+	for _, r := range ranges {
+		total += r.length
+	}
+	return total <= size
+}
+
+// ifRangeMatches reports whether the If-Range condition of req holds, so the
+// Range header applies (RFC 9110 13.1.5). An entity tag must match strongly;
+// a date must equal the Last-Modified time. Without If-Range it holds.
+func ifRangeMatches(req *Request, etag string, modtime time.Time) bool {
+	value, err := req.Headers.Get("If-Range")
+	if err != nil {
+		return true
+	}
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "W/") {
+		return etagMatches([]string{value}, etag, false)
+	}
+	t, err := ParseTime(value)
+	return err == nil && !modtime.IsZero() && t.Equal(modtime.Truncate(time.Second))
+}
+
+// requestedRanges returns the ranges to send for content of the given size,
+// or nil to send all of it. Range only applies to GET.
+func (w *ResponseWriter) requestedRanges(etag string, modtime time.Time, size int64) ([]byteRange, *types.RouteError) {
+	if w.req == nil || w.req.RequestLine.Method != types.GET {
+		return nil, nil
+	}
+	header, err := w.req.Headers.Get("Range")
+	if err != nil || !ifRangeMatches(w.req, etag, modtime) {
+		return nil, nil
+	}
+	ranges, err := parseRange(header, size)
+	switch {
+	case errors.Is(err, errRangeUnsatisfied):
+		// the body is the error text, whatever the content type was
+		w.Headers.Del("Content-Type")
+		w.Headers.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		return nil, &types.RouteError{Code: types.RangeNotSatisfiable, Message: "Range Not Satisfiable"}
+	case err != nil, !rangesWorthServing(ranges, size):
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+// sendRanges answers 206 with the requested ranges of content: the range
+// itself for a single one, a multipart/byteranges body for several.
+func (w *ResponseWriter) sendRanges(content io.ReadSeeker, size int64, ranges []byteRange) *types.RouteError {
+	w.Status = types.PartialContent
+	if len(ranges) == 1 {
+		w.Headers.Set("Content-Range", ranges[0].contentRange(size))
+		w.Headers.Set("Content-Length", strconv.FormatInt(ranges[0].length, 10))
+		return w.streamRanges(content, ranges, nil)
+	}
+
+	// every part repeats the Content-Type of the whole
+	contentType, _ := w.Headers.Get("Content-Type")
+	boundary := rand.Text()
+	parts := make([]string, len(ranges))
+	length := int64(len("--" + boundary + "--\r\n"))
+	for i, r := range ranges {
+		parts[i] = fmt.Sprintf("--%s\r\nContent-Type: %s\r\nContent-Range: %s\r\n\r\n", boundary, contentType, r.contentRange(size))
+		length += int64(len(parts[i])) + r.length + int64(len("\r\n"))
+	}
+	w.Headers.Set("Content-Type", "multipart/byteranges; boundary="+boundary)
+	w.Headers.Set("Content-Length", strconv.FormatInt(length, 10))
+	parts = append(parts, "--"+boundary+"--\r\n")
+	return w.streamRanges(content, ranges, parts)
+}
+
+// streamRanges writes the head and then every range, each one preceded by
+// its part header when parts is given; parts then also holds the closing
+// delimiter.
+func (w *ResponseWriter) streamRanges(content io.ReadSeeker, ranges []byteRange, parts []string) *types.RouteError {
+	if err := w.writeHead(); err != nil {
+		return err
+	}
+	if w.isHead {
+		return nil
+	}
+	for i, r := range ranges {
+		if parts != nil {
+			if _, err := io.WriteString(w.write, parts[i]); err != nil {
+				return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
+			}
+		}
+		if _, err := content.Seek(r.start, io.SeekStart); err != nil {
+			return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
+		}
+		if _, err := io.CopyN(w.write, content, r.length); err != nil {
+			return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
+		}
+		if parts != nil {
+			if _, err := io.WriteString(w.write, "\r\n"); err != nil {
+				return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
+			}
+		}
+	}
+	if parts != nil {
+		if _, err := io.WriteString(w.write, parts[len(parts)-1]); err != nil {
+			return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	nethttp "net/http"
+	"slices"
+	"testing"
+	"time"
+
+	types "myserver/internals/type"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		size   int64
+		want   []byteRange
+		err    error
+	}{
+		{header: "bytes=0-4", size: 10, want: []byteRange{{0, 5}}},
+		{header: "bytes=5-", size: 10, want: []byteRange{{5, 5}}},
+		{header: "bytes=-3", size: 10, want: []byteRange{{7, 3}}},
+		{header: "bytes=-30", size: 10, want: []byteRange{{0, 10}}},
+		{header: "bytes=8-20", size: 10, want: []byteRange{{8, 2}}},
+		{header: "bytes=0-0,-1", size: 10, want: []byteRange{{0, 1}, {9, 1}}},
+		{header: "bytes= 0-1 , 4-5 ", size: 10, want: []byteRange{{0, 2}, {4, 2}}},
+		// overlapping ranges are kept as sent
+		{header: "bytes=0-5,3-8", size: 10, want: []byteRange{{0, 6}, {3, 6}}},
+		// unsatisfiable ranges are dropped while others remain
+		{header: "bytes=20-30,0-1", size: 10, want: []byteRange{{0, 2}}},
+		{header: "bytes=10-", size: 10, err: errRangeUnsatisfied},
+		{header: "bytes=50-60,70-", size: 10, err: errRangeUnsatisfied},
+		{header: "bytes=-0", size: 10, err: errRangeUnsatisfied},
+		{header: "bytes=0-", size: 0, err: errRangeUnsatisfied},
+		{header: "bytes=-5", size: 0, err: errRangeUnsatisfied},
+		{header: "bytes=5-4", size: 10, err: errInvalidRange},
+		{header: "bytes=a-b", size: 10, err: errInvalidRange},
+		{header: "bytes=--5", size: 10, err: errInvalidRange},
+		{header: "bytes=5", size: 10, err: errInvalidRange},
+		{header: "items=0-5", size: 10, err: errInvalidRange},
+		{header: "0-5", size: 10, err: errInvalidRange},
+	}
+	for _, tt := range tests {
+		got, err := parseRange(tt.header, tt.size)
+		if !errors.Is(err, tt.err) || !slices.Equal(got, tt.want) {
+			t.Errorf("parseRange(%q, %d) = %v, %v; want %v, %v", tt.header, tt.size, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestRangesWorthServing(t *testing.T) {
+	if !rangesWorthServing([]byteRange{{0, 5}, {5, 5}}, 10) {
+		t.Error("ranges covering the content once were refused")
+	}
+	if rangesWorthServing([]byteRange{{0, 8}, {2, 8}}, 10) {
+		t.Error("overlapping ranges larger than the content were served")
+	}
+}
+
+// sendBytes answers a GET for data through SendBytes and parses the result.
+func sendBytes(t *testing.T, name string, data []byte, modtime time.Time, method types.Method, headers ...string) (*nethttp.Response, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewResponseWriter(&buf, 0)
+	w.SetRequest(headRequest(t, method, headers...))
+	if routeErr := w.SendBytes(name, modtime, data); routeErr != nil {
+		w.SendError(routeErr.Code, routeErr.Message)
+	}
+	resp, err := nethttp.ReadResponse(bufio.NewReader(&buf), &nethttp.Request{Method: string(method)})
+	if err != nil {
+		t.Fatalf("unreadable response %q: %v", buf.String(), err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	return resp, body
+}
+
+func TestSendBytesRanges(t *testing.T) {
+	data := []byte("0123456789")
+	modtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	etag := ETag(modtime, int64(len(data)))
+
+	tests := []struct {
+		name         string
+		headers      []string
+		status       int
+		body         string
+		contentRange string
+	}{
+		{name: "no range", status: 200, body: "0123456789"},
+		{name: "single range", headers: []string{"Range: bytes=2-4"}, status: 206, body: "234", contentRange: "bytes 2-4/10"},
+		{name: "suffix range", headers: []string{"Range: bytes=-2"}, status: 206, body: "89", contentRange: "bytes 8-9/10"},
+		{name: "invalid range is ignored", headers: []string{"Range: bytes=4-2"}, status: 200, body: "0123456789"},
+		{name: "unsatisfiable", headers: []string{"Range: bytes=50-"}, status: 416, body: "Range Not Satisfiable", contentRange: "bytes */10"},
+		{name: "If-Range etag hit", headers: []string{"Range: bytes=0-1", "If-Range: " + etag}, status: 206, body: "01", contentRange: "bytes 0-1/10"},
+		{name: "If-Range etag miss", headers: []string{"Range: bytes=0-1", `If-Range: "old"`}, status: 200, body: "0123456789"},
+		{name: "If-Range weak etag", headers: []string{"Range: bytes=0-1", "If-Range: W/" + etag}, status: 200, body: "0123456789"},
+		{name: "If-Range date hit", headers: []string{"Range: bytes=0-1", "If-Range: " + modtime.Format(TimeFormat)}, status: 206, body: "01", contentRange: "bytes 0-1/10"},
+		{name: "If-Range date miss", headers: []string{"Range: bytes=0-1", "If-Range: " + modtime.Add(-time.Hour).Format(TimeFormat)}, status: 200, body: "0123456789"},
+		{name: "overlapping ranges larger than the content", headers: []string{"Range: bytes=0-7,2-9"}, status: 200, body: "0123456789"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := sendBytes(t, "image.jpg", data, modtime, types.GET, tt.headers...)
+			if resp.StatusCode != tt.status || string(body) != tt.body {
+				t.Fatalf("got %d %q, want %d %q", resp.StatusCode, body, tt.status, tt.body)
+			}
+			if got := resp.Header.Get("Content-Range"); got != tt.contentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.contentRange)
+			}
+			wantType := "image/jpeg"
+			if tt.status == 416 {
+				wantType = string(types.TextPlain)
+			}
+			if got := resp.Header.Get("Content-Type"); got != wantType {
+				t.Errorf("Content-Type = %q, want %q", got, wantType)
+			}
+		})
+	}
+}
+
+func TestSendBytesMultipleRanges(t *testing.T) {
+	data := []byte("0123456789")
+	resp, body := sendBytes(t, "notes.txt", data, time.Time{}, types.GET, "Range: bytes=0-1,5-6,-1")
+	if resp.StatusCode != 206 {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if resp.ContentLength != int64(len(body)) {
+		t.Errorf("Content-Length = %d, body is %d bytes", resp.ContentLength, len(body))
+	}
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/byteranges" {
+		t.Fatalf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	want := []struct{ contentRange, body string }{
+		{"bytes 0-1/10", "01"},
+		{"bytes 5-6/10", "56"},
+		{"bytes 9-9/10", "9"},
+	}
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for i, w := range want {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		got, _ := io.ReadAll(part)
+		if part.Header.Get("Content-Range") != w.contentRange || string(got) != w.body {
+			t.Errorf("part %d = %q %q, want %q %q", i, part.Header.Get("Content-Range"), got, w.contentRange, w.body)
+		}
+		if ct := part.Header.Get("Content-Type"); ct != string(types.AppOctet) {
+			t.Errorf("part %d Content-Type = %q", i, ct)
+		}
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("after the last part: %v, want io.EOF", err)
+	}
+}
+
+func TestSendBytesHeadIgnoresRange(t *testing.T) {
+	resp, body := sendBytes(t, "a.txt", []byte("0123456789"), time.Time{}, types.HEAD, "Range: bytes=0-1")
+	if resp.StatusCode != 200 || len(body) != 0 || resp.Header.Get("Accept-Ranges") != "bytes" {
+		t.Errorf("HEAD = %d %q Accept-Ranges %q", resp.StatusCode, body, resp.Header.Get("Accept-Ranges"))
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"io"
+
+	types "myserver/internals/type"
+)
+
+// Reader parses consecutive requests from one connection. It keeps the bytes
+// read past the end of a request, so pipelined requests (several requests
+// sent before the first response) and fast keep-alive clients lose nothing.
+//
+// Requests must be handled one at a time: ReadRequest finishes the previous
+// request's body before parsing the next one, which also means responses are
+// written in the order the requests arrived.
+type Reader struct {
+	r      io.Reader
+	buf    []byte
+	n      int
+	limits Limits
+	last   *Request
+}
+
+func NewReader(r io.Reader, limits Limits) *Reader {
+	return &Reader{
+		r:      r,
+		buf:    make([]byte, DefaultBufferSize),
+		limits: limits.withDefaults(),
+	}
+}
+
+// Buffered returns the number of bytes already read but not yet parsed.
+func (b *Reader) Buffered() int {
+	return b.n
+}
+
+// ReadRequest parses the next request. The body is left on the connection
+// and read lazily through Request.Body; whatever the caller did not read is
+// discarded here before the next request line is parsed.
+//
+// io.EOF is returned when the connection is closed cleanly between requests,
+// io.ErrUnexpectedEOF when it is closed in the middle of one.
+func (b *Reader) ReadRequest() (*Request, error) {
+	if b.last != nil {
+		if err := b.last.Body.Close(); err != nil {
+			return nil, err
+		}
+		b.last = nil
+	}
+
+	req := NewRequestParser()
+	req.limits = b.limits
+	for {
+		consumed, err := req.parsing(b.data())
+		if err != nil {
+			return nil, err
+		}
+		b.consume(consumed)
+		if req.headDone() {
+			break
+		}
+		if err := b.fill(); err != nil {
+			if errors.Is(err, io.EOF) && (b.n > 0 || req.status != types.StateRequestLine) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	req.Body = &body{req: req, src: b, remaining: req.ContentLength}
+	b.last = req
+	return req, nil
+}
+
+// data returns the buffered bytes that have not been consumed yet.
+func (b *Reader) data() []byte {
+	return b.buf[:b.n]
+}
+
+// fill reads more data from the underlying reader into the buffer, growing it
+// when it is full. The parser rejects oversized input before asking for more,
+// so the buffer stays bounded by the configured limits.
+func (b *Reader) fill() error {
+	if b.n == len(b.buf) {
+		grown := make([]byte, 2*len(b.buf))
+		copy(grown, b.buf[:b.n])
+		b.buf = grown
+	}
+	n, err := b.r.Read(b.buf[b.n:])
+	b.n += n
+	if n > 0 {
+		return nil
+	}
+	if err == nil {
+		err = io.ErrNoProgress
+	}
+	return err
+}
+
+// consume drops the first n buffered bytes and moves the leftover data to the
+// front so the buffer can be reused for the next read.
+func (b *Reader) consume(n int) {
+	copy(b.buf, b.buf[n:b.n])
+	b.n -= n
+}
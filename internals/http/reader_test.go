@@ -0,0 +1,118 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderPipelined(t *testing.T) {
+	input := "GET /first HTTP/1.1\r\nHost: example.com\r\n\r\n" +
+		"POST /second HTTP/1.1\r\nHost: example.com\r\nContent-Length: 5\r\n\r\nhello" +
+		"POST /third HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n0\r\n\r\n" +
+		"GET /fourth HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	tests := []struct {
+		path string
+		body string
+		// skip leaves the body unread, so ReadRequest has to discard it
+		skip bool
+	}{
+		{path: "/first"},
+		{path: "/second", body: "hello"},
+		{path: "/third", skip: true},
+		{path: "/fourth"},
+	}
+
+	readers := map[string]func() io.Reader{
+		"all at once":      func() io.Reader { return strings.NewReader(input) },
+		"one byte at once": func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	}
+	for name, reader := range readers {
+		t.Run(name, func(t *testing.T) {
+			r := NewReader(reader(), DefaultLimits)
+			for _, tt := range tests {
+				req, err := r.ReadRequest()
+				if err != nil {
+					t.Fatalf("ReadRequest %s: %v", tt.path, err)
+				}
+				if req.URL.Path != tt.path {
+					t.Fatalf("path = %q, want %q", req.URL.Path, tt.path)
+				}
+				if tt.skip {
+					continue
+				}
+				body, err := io.ReadAll(req.Body)
+				if err != nil || string(body) != tt.body {
+					t.Fatalf("%s body = %q, %v; want %q", tt.path, body, err, tt.body)
+				}
+			}
+			if _, err := r.ReadRequest(); err != io.EOF {
+				t.Errorf("ReadRequest after the last request = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestReaderEOF(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"closed between requests", "", io.EOF},
+		{"closed in the request line", "GET /", io.ErrUnexpectedEOF},
+		{"closed in the headers", "GET / HTTP/1.1\r\nHost: a", io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.input), DefaultLimits).ReadRequest()
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ReadRequest = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestReaderLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		limits Limits
+		err    error
+	}{
+		{
+			name:   "request line",
+			input:  "GET /" + strings.Repeat("a", 64) + " HTTP/1.1\r\n\r\n",
+			limits: Limits{MaxRequestLineBytes: 32},
+			err:    ErrRequestLineTooLong,
+		},
+		{
+			name:   "header bytes",
+			input:  "GET / HTTP/1.1\r\nHost: example.com\r\nX-Big: " + strings.Repeat("a", 64) + "\r\n\r\n",
+			limits: Limits{MaxHeaderBytes: 32},
+			err:    ErrHeaderTooLarge,
+		},
+		{
+			name:   "header count",
+			input:  "GET / HTTP/1.1\r\nHost: example.com\r\nA: 1\r\nB: 2\r\n\r\n",
+			limits: Limits{MaxHeaderCount: 2},
+			err:    ErrHeaderTooLarge,
+		},
+		{
+			name:   "content length",
+			input:  "POST / HTTP/1.1\r\nHost: example.com\r\nContent-Length: 100\r\n\r\n",
+			limits: Limits{MaxBodyBytes: 10},
+			err:    ErrRequestTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.input), tt.limits).ReadRequest()
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ReadRequest = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -19,10 +19,33 @@ type RequestLine struct {
 }
 type Request struct {
 	RequestLine RequestLine
-	Body        []byte
-	Headers     Header
-	status      types.ParseState
-	Params      url.Params
+	// Body streams the request body straight from the connection. It is
+	// never nil; requests without a body return io.EOF right away.
+	Body io.ReadCloser
+	// ContentLength is the declared body size, or -1 for a chunked body.
+	ContentLength int64
+	Headers       Header
+	// Trailer holds the fields sent after the last chunk of a chunked body.
+	// It is only complete once Body has been read to io.EOF.
+	Trailer Header
+	// URL is the parsed request target: its decoded path and its query.
+	// RequestLine.Path keeps the target as sent.
+	URL    url.URL
+	Params url.Params
+	// Host is the target host: the authority of an absolute-form or
+	// authority-form request target, otherwise the Host header. It may
+	// include a port.
+	Host string
+	// RoutePath is the path left to route once a mounted router has
+	// stripped its prefix. Empty means URL.Path.
+	RoutePath string
+	// Pattern is the pattern of the route serving the request, as
+	// registered on the innermost router. Empty when no route matched.
+	Pattern string
+	status  types.ParseState
+	limits  Limits
+	// cleanups run by Finish, e.g. removing a multipart form's temp files
+	cleanups []func() error
 }
 
 func NewRequestParser() *Request {
@@ -30,9 +53,30 @@ func NewRequestParser() *Request {
 		status:  types.StateRequestLine,
 		Body:    nil,
 		Headers: make(Header),
+		Trailer: make(Header),
+		limits:  DefaultLimits,
 	}
 }
 
+// ReadBody reads the rest of the body into memory. It is meant for handlers
+// that want the whole payload at once; the body can only be consumed once.
+func (req *Request) ReadBody() ([]byte, error) {
+	return io.ReadAll(req.Body)
+}
+
+// Finish releases what was acquired while handling the request, such as the
+// temporary files of a multipart form bound with Bind. The server calls it
+// once the response is sent; handlers must not keep uploaded files past
+// that point.
+func (req *Request) Finish() error {
+	var errs []error
+	for _, cleanup := range req.cleanups {
+		errs = append(errs, cleanup())
+	}
+	req.cleanups = nil
+	return errors.Join(errs...)
+}
+
 func (req *Request) IsKeepAlive() bool {
 	switch req.RequestLine.Version {
 	case types.HTTP1_0:
@@ -53,10 +97,16 @@ func (req *Request) parseRequestLine(data []byte) (int, error) {
 	// method path version \r\n
 	idx := bytes.Index(data, []byte(SEPARATOR))
 	// we did not get the full line
-	// so do not return error
+	// so do not return error, unless it is already too long
 	if idx == -1 {
+		if len(data) > req.limits.MaxRequestLineBytes {
+			return 0, ErrRequestLineTooLong
+		}
 		return 0, nil
 	}
+	if idx > req.limits.MaxRequestLineBytes {
+		return 0, ErrRequestLineTooLong
+	}
 	parts := bytes.Split(data[:idx], []byte(" "))
 	if len(parts) != 3 {
 		return 0, fmt.Errorf("%w: expected 3 parts, got %d", ErrInvalidRequestLine, len(parts))
@@ -74,17 +124,33 @@ func (req *Request) parseRequestLine(data []byte) (int, error) {
 	req.RequestLine.Path = string(parts[1])
 	return idx + len(SEPARATOR), nil
 }
-func (req *Request) parseRequestHeader(data []byte) (int, error) {
+
+// parseFields parses a block of "name: value" lines terminated by an empty
+// line into dst. It is shared by the header section and the chunked trailer,
+// and both are bounded by the header limits.
+func parseFields(data []byte, dst Header, limits Limits) (int, error) {
+	// empty block: the terminating CRLF comes right away
+	if bytes.HasPrefix(data, []byte(SEPARATOR)) {
+		return len(SEPARATOR), nil
+	}
 	Idx := bytes.Index(data, []byte(SEPARATOR+SEPARATOR))
 	if Idx == -1 {
+		if len(data) > limits.MaxHeaderBytes {
+			return 0, ErrHeaderTooLarge
+		}
 		return 0, nil
 	}
-	HeadersBytes := data[:Idx]
-	if len(HeadersBytes) == 0 {
-		return Idx + len(SEPARATOR+SEPARATOR), nil
+	if Idx > limits.MaxHeaderBytes {
+		return 0, ErrHeaderTooLarge
 	}
+	HeadersBytes := data[:Idx]
 
+	count := 0
 	for line := range bytes.SplitSeq(HeadersBytes, []byte(SEPARATOR)) {
+		count++
+		if count > limits.MaxHeaderCount {
+			return 0, fmt.Errorf("%w: more than %d fields", ErrHeaderTooLarge, limits.MaxHeaderCount)
+		}
 		parts := bytes.SplitN(line, []byte(":"), 2)
 		if len(parts) != 2 {
 			return 0, fmt.Errorf("%w: invalid header line %q", ErrInvalidHeader, line)
@@ -94,12 +160,93 @@ func (req *Request) parseRequestHeader(data []byte) (int, error) {
 		if len(key) == 0 || bytes.Contains(key, []byte(" ")) {
 			return 0, fmt.Errorf("%w: invalid header name %q", ErrInvalidHeaderName, key)
 		}
-		req.Headers.Set(string(key), string(value))
+		dst.Add(string(key), string(value))
 	}
 
 	return Idx + len(SEPARATOR+SEPARATOR), nil
+}
+
+func (req *Request) parseRequestHeader(data []byte) (int, error) {
+	return parseFields(data, req.Headers, req.limits)
+}
+
+// parseTarget parses the request target into req.URL. Absolute-form and
+// authority-form targets also carry the host. The asterisk-form is only
+// meaningful for OPTIONS.
+func (req *Request) parseTarget() error {
+	method := req.RequestLine.Method
+	target, err := url.ParseTarget(req.RequestLine.Path, method == types.CONNECT)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRequestLine, err)
+	}
+	if target.Form == url.AsteriskForm && method != types.OPTIONS {
+		return fmt.Errorf("%w: %q only applies to OPTIONS", ErrInvalidRequestLine, "*")
+	}
+	req.URL = target.URL
+	req.Host = target.Host
+	return nil
+}
+
+// parseHost fills req.Host from the Host header. A host given by the target
+// wins over the header (RFC 9112 3.2.2). HTTP/1.1 requires exactly one Host
+// header.
+func (req *Request) parseHost() error {
+	hosts := req.Headers.Values("Host")
+	switch {
+	case len(hosts) > 1:
+		return fmt.Errorf("%w: multiple Host headers", ErrInvalidHeader)
+	case req.Host != "":
+	case len(hosts) == 1:
+		req.Host = hosts[0]
+	case req.RequestLine.Version == types.HTTP1_1:
+		return ErrMissingHost
+	}
+	return nil
+}
+
+// bodyState picks how the body is framed once the headers are known.
+// Only a lone "chunked" transfer coding is decoded; any other coding would
+// reach the handler still encoded. A request carrying both Transfer-Encoding
+// and Content-Length is rejected, since peers may disagree on where it ends
+// (RFC 9112 6.1).
+func (req *Request) bodyState() (types.ParseState, error) {
+	// repeated Transfer-Encoding lines form one comma-separated list
+	if te := strings.Join(req.Headers.Values("Transfer-Encoding"), ","); te != "" {
+		if !strings.EqualFold(strings.TrimSpace(te), "chunked") {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedTransferCoding, te)
+		}
+		if req.Headers.Has("Content-Length") {
+			return "", fmt.Errorf("%w: both Transfer-Encoding and Content-Length", ErrInvalidHeader)
+		}
+		req.ContentLength = -1
+		return types.StateChunkedBody, nil
+	}
 
+	lengths := req.Headers.Values("Content-Length")
+	if len(lengths) == 0 {
+		return types.StateDone, nil
+	}
+	// repeated Content-Length lines are only acceptable if they agree
+	length := lengths[0]
+	for _, l := range lengths[1:] {
+		if l != length {
+			return "", fmt.Errorf("%w: conflicting Content-Length values", ErrInvalidHeader)
+		}
+	}
+	n, err := strconv.ParseInt(length, 10, 64)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("%w: invalid Content-Length %q", ErrInvalidHeader, length)
+	}
+	if n > req.limits.MaxBodyBytes {
+		return "", ErrRequestTooLarge
+	}
+	req.ContentLength = n
+	if n == 0 {
+		return types.StateDone, nil
+	}
+	return types.StateBody, nil
 }
+
 func (req *Request) parsing(data []byte) (int, error) {
 	consumed := 0
 	for {
@@ -126,60 +273,55 @@ func (req *Request) parsing(data []byte) (int, error) {
 				return consumed, nil
 			}
 			consumed += headerLen
-			req.status = types.StateBody
-		case types.StateBody:
-			length, err := req.Headers.Get("Content-Length")
-			if err != nil {
-				req.status = types.StateDone
-			}
-			n, err := strconv.Atoi(length)
-			if err != nil || n == 0 {
-				req.status = types.StateDone
+			if err := req.parseTarget(); err != nil {
+				return consumed, err
 			}
-			readLength := min(n-len(req.Body), len(currentData))
-			if readLength == 0 {
-				// We've read everything available so far, but not the full body
-				// Return control so caller can read more data into buffer
-				return consumed, nil
+			if err := req.parseHost(); err != nil {
+				return consumed, err
 			}
-			req.Body = append(req.Body, currentData...)
-			consumed += readLength
-			if len(req.Body) >= n {
-				req.status = types.StateDone
+			state, err := req.bodyState()
+			if err != nil {
+				return consumed, err
 			}
-		case types.StateDone:
+			req.status = state
+		default:
+			// the body is read lazily through req.Body
 			return consumed, nil
 		}
 	}
 }
-func ParseRequest(reader io.Reader) (*Request, error) {
-	buff := make([]byte, DefaultBufferSize)
-	req := NewRequestParser()
-	buffLen := 0
-	for req.status != types.StateDone {
-
-		bytesRead, err := reader.Read(buff[buffLen:])
-		if err != nil {
-			return nil, err
-		}
-		buffLen += bytesRead
-
-		consumed, err := req.parsing(buff[:buffLen])
-		if err != nil {
-			// if the error EOF. change the status to Done
-			if errors.Is(err, io.EOF) {
-				req.status = types.StateDone
-				break
-			}
-			return nil, err
-		}
 
-		// Move leftover data (buff[consumed:buffLen]) to the front (index 0)
-		// so we can reuse the buffer for the next read.
-		copy(buff, buff[consumed:buffLen])
-		buffLen -= consumed
-	}
+// headDone reports whether the request line and headers have been parsed.
+func (req *Request) headDone() bool {
+	return req.status != types.StateRequestLine && req.status != types.StateHeader
+}
 
-	return req, nil
+// ParseRequest reads a single request from reader. Any bytes read past the
+// end of the request are lost; use a Reader to parse several requests from
+// the same connection.
+func ParseRequest(reader io.Reader) (*Request, error) {
+	return ParseRequestWithLimits(reader, DefaultLimits)
+}
 
+// ParseRequestWithLimits is like ParseRequest but enforces the given limits.
+// Violations are reported as ErrRequestLineTooLong, ErrHeaderTooLarge or
+// ErrRequestTooLarge; see ErrorStatus for the matching status codes.
+func ParseRequestWithLimits(reader io.Reader, limits Limits) (*Request, error) {
+	return NewReader(reader, limits).ReadRequest()
+}
+
+// ErrorStatus maps a parse error to the status code it should be answered with.
+func ErrorStatus(err error) types.StatusCode {
+	switch {
+	case errors.Is(err, ErrRequestLineTooLong):
+		return types.URITooLong
+	case errors.Is(err, ErrHeaderTooLarge):
+		return types.RequestHeaderFieldsTooLarge
+	case errors.Is(err, ErrRequestTooLarge):
+		return types.ContentTooLarge
+	case errors.Is(err, ErrUnsupportedTransferCoding):
+		return types.NotImplemented
+	default:
+		return types.BadRequest
+	}
 }
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	types "myserver/internals/type"
+)
+
+func TestParseHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		host    string
+		err     error
+	}{
+		{
+			name:    "Host header",
+			request: "GET / HTTP/1.1\r\nHost: API.example.test:8080\r\n",
+			host:    "API.example.test:8080",
+		},
+		{
+			name:    "absolute-form wins over the header",
+			request: "GET http://a.example.test/ HTTP/1.1\r\nHost: b.example.test\r\n",
+			host:    "a.example.test",
+		},
+		{
+			name:    "HTTP/1.0 may leave it out",
+			request: "GET / HTTP/1.0\r\n",
+		},
+		{
+			name:    "HTTP/1.1 without Host",
+			request: "GET / HTTP/1.1\r\n",
+			err:     ErrMissingHost,
+		},
+		{
+			name:    "two Host headers",
+			request: "GET / HTTP/1.1\r\nHost: a.example.test\r\nHost: b.example.test\r\n",
+			err:     ErrInvalidHeader,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParseRequest(strings.NewReader(tt.request + "\r\n"))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ParseRequest error = %v, want %v", err, tt.err)
+				}
+				if status := ErrorStatus(err); status != types.BadRequest {
+					t.Errorf("ErrorStatus = %d, want %d", status, types.BadRequest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRequest: %v", err)
+			}
+			if req.Host != tt.host {
+				t.Errorf("Host = %q, want %q", req.Host, tt.host)
+			}
+		})
+	}
+}
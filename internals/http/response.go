@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	types "myserver/internals/type"
 	"os"
 	"path/filepath"
@@ -17,6 +18,8 @@ type ResponseWriter struct {
 	write       io.Writer
 	idleTimeout time.Duration
 	isKeepAlive bool
+	isHead      bool
+	req         *Request
 	Version     types.Version
 	Status      types.StatusCode
 	Headers     *Header
@@ -37,6 +40,20 @@ func (w *ResponseWriter) SetKeppAlive(isAlive bool) {
 	w.isKeepAlive = isAlive
 }
 
+// SetHead marks the response as an answer to a HEAD request: the status line
+// and headers (including Content-Length) are sent, the body is not.
+func (w *ResponseWriter) SetHead(isHead bool) {
+	w.isHead = isHead
+}
+
+// SetRequest tells the writer which request it answers. SendFile and
+// SendContent evaluate its conditional headers, and a HEAD request gets no
+// body (see SetHead).
+func (w *ResponseWriter) SetRequest(req *Request) {
+	w.req = req
+	w.isHead = req.RequestLine.Method == types.HEAD
+}
+
 func (w *ResponseWriter) WriteStatusLine() error {
 	code := w.Status
 	text, ok := types.StatusText[code]
@@ -49,16 +66,23 @@ func (w *ResponseWriter) WriteStatusLine() error {
 
 // WriteHeader writes headers to the response
 func (w *ResponseWriter) WriteHeader() error {
-	for key, value := range *w.Headers {
-		if _, err := fmt.Fprintf(w.write, "%s: %s\r\n", key, value); err != nil {
-			return err
+	var err error
+	w.Headers.ForEach(func(key, value string) {
+		if err == nil {
+			_, err = fmt.Fprintf(w.write, "%s: %s\r\n", key, value)
 		}
+	})
+	if err != nil {
+		return err
 	}
-	_, err := fmt.Fprint(w.write, "\r\n")
+	_, err = fmt.Fprint(w.write, "\r\n")
 	return err
 }
 
 func (w *ResponseWriter) WriteBody(data []byte) error {
+	if w.isHead {
+		return nil
+	}
 	_, err := w.write.Write(data)
 	return err
 }
@@ -118,18 +142,18 @@ func getContentTypeFromExtension(path string) types.ContentType {
 }
 
 func (w *ResponseWriter) SetDefaultHeaders(body *[]byte) {
-	// Content-Length
-	if _, exists := (*w.Headers)["Content-Length"]; !exists {
+	// Content-Length (never sent with 204 No Content or 304 Not Modified)
+	if w.Status != types.NoContent && w.Status != types.NotModified && !w.Headers.Has("Content-Length") {
 		w.Headers.Set("Content-Length", strconv.Itoa(len(*body)))
 	}
 
 	// Date
-	if _, exists := (*w.Headers)["Date"]; !exists {
-		w.Headers.Set("Date", time.Now().UTC().Format(time.RFC1123))
+	if !w.Headers.Has("Date") {
+		w.Headers.Set("Date", time.Now().UTC().Format(TimeFormat))
 	}
 
 	// Connection / Keep-Alive
-	if _, exists := (*w.Headers)["Connection"]; !exists {
+	if !w.Headers.Has("Connection") {
 		if w.isKeepAlive {
 			w.Headers.Set("Connection", "keep-alive")
 			w.Headers.Set("Keep-Alive", fmt.Sprintf("timeout=%d", int(w.idleTimeout.Seconds())))
@@ -140,7 +164,7 @@ func (w *ResponseWriter) SetDefaultHeaders(body *[]byte) {
 
 	// Content-Type
 	if len(*body) > 0 {
-		if _, exists := (*w.Headers)["Content-Type"]; !exists {
+		if !w.Headers.Has("Content-Type") {
 			ct := detectContentType(body)
 			w.Headers.Set("Content-Type", string(ct))
 		}
@@ -163,39 +187,133 @@ func (w *ResponseWriter) SendResponse(body []byte) *types.RouteError {
 	}
 	return nil
 }
+
+// SendFile sends the file at path, with a Content-Type guessed from its
+// extension unless one is already set.
 func (w *ResponseWriter) SendFile(path string) *types.RouteError {
 	file, err := os.Open(path)
+	if err != nil {
+		return &types.RouteError{Code: types.NotFound, Message: "File not found"}
+	}
+	return w.sendFile(path, file)
+}
 
+// SendFileFS is like SendFile for the file name in fsys, such as an
+// embed.FS. name is slash-separated and has no leading slash.
+func (w *ResponseWriter) SendFileFS(fsys fs.FS, name string) *types.RouteError {
+	file, err := fsys.Open(name)
 	if err != nil {
 		return &types.RouteError{Code: types.NotFound, Message: "File not found"}
 	}
+	return w.sendFile(name, file)
+}
+
+// sendFile sends and closes file. Its size comes from Stat.
+func (w *ResponseWriter) sendFile(name string, file fs.File) *types.RouteError {
 	defer file.Close()
 
 	fi, err := file.Stat()
 	if err != nil {
 		return &types.RouteError{Code: types.InternalServerError, Message: "Failed to read file info"}
 	}
+	if fi.IsDir() {
+		return &types.RouteError{Code: types.NotFound, Message: "File not found"}
+	}
+
+	return w.SendContent(name, fi.ModTime(), fi.Size(), file)
+}
+
+// SendContent streams size bytes from content as the body. name is only
+// used to pick a Content-Type by extension when none is set.
+//
+// A non-zero modtime is sent as Last-Modified and, unless an ETag header is
+// already set, gives the content a strong ETag. The conditional headers of
+// the request (see SetRequest) are then evaluated against these validators,
+// answering 304 Not Modified without a body or failing with 412
+// Precondition Failed.
+//
+// When content is also an io.Seeker, byte ranges are supported: GET requests
+// with a Range header (subject to If-Range) get 206 Partial Content, as a
+// multipart/byteranges body for several ranges, or fail with 416 Range Not
+// Satisfiable.
+func (w *ResponseWriter) SendContent(name string, modtime time.Time, size int64, content io.Reader) *types.RouteError {
+	if !modtime.IsZero() {
+		if !w.Headers.Has("Last-Modified") {
+			w.Headers.Set("Last-Modified", modtime.UTC().Format(TimeFormat))
+		}
+		if !w.Headers.Has("ETag") {
+			w.Headers.Set("ETag", ETag(modtime, size))
+		}
+	}
+	etag, _ := w.Headers.Get("ETag")
+	switch checkPreconditions(w.req, etag, modtime) {
+	case types.NotModified:
+		return w.sendNotModified()
+	case types.PreconditionFailed:
+		return &types.RouteError{Code: types.PreconditionFailed, Message: "Precondition Failed"}
+	}
 
-	w.Headers.Set("Content-Length", strconv.FormatInt(fi.Size(), 10))
-	if _, exists := (*w.Headers)["Content-Type"]; !exists {
-		ct := getContentTypeFromExtension(filepath.Ext(path))
+	// ranges are worked out before the content headers are set, so a 416
+	// goes out as a plain error
+	seeker, seekable := content.(io.ReadSeeker)
+	var ranges []byteRange
+	if seekable {
+		var routeErr *types.RouteError
+		if ranges, routeErr = w.requestedRanges(etag, modtime, size); routeErr != nil {
+			return routeErr
+		}
+		w.Headers.Set("Accept-Ranges", "bytes")
+	}
+	if !w.Headers.Has("Content-Type") {
+		ct := getContentTypeFromExtension(filepath.Ext(name))
 		w.Headers.Set("Content-Type", string(ct))
 	}
+	if ranges != nil {
+		return w.sendRanges(seeker, size, ranges)
+	}
 
-	if err := w.WriteStatusLine(); err != nil {
-		return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
+	w.Headers.Set("Content-Length", strconv.FormatInt(size, 10))
+	if err := w.writeHead(); err != nil {
+		return err
 	}
-	if err := w.WriteHeader(); err != nil {
+	if w.isHead {
+		return nil
+	}
+	if _, err := io.CopyN(w.write, content, size); err != nil {
 		return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
 	}
 
-	if _, err := io.Copy(w.write, file); err != nil {
+	return nil
+}
+
+// SendBytes is SendContent for a body already in memory, so it gets the
+// same validators and range support as a file.
+func (w *ResponseWriter) SendBytes(name string, modtime time.Time, data []byte) *types.RouteError {
+	return w.SendContent(name, modtime, int64(len(data)), bytes.NewReader(data))
+}
+
+// writeHead writes the status line and the headers of a streamed body,
+// whose Content-Length and Content-Type are already set.
+func (w *ResponseWriter) writeHead() *types.RouteError {
+	// Date and Connection
+	w.SetDefaultHeaders(&[]byte{})
+	if err := w.WriteStatusLine(); err != nil {
+		return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
+	}
+	if err := w.WriteHeader(); err != nil {
 		return &types.RouteError{Code: types.InternalServerError, Message: err.Error()}
 	}
-
 	return nil
 }
 
+// sendNotModified answers 304 with the validators already set and no body.
+func (w *ResponseWriter) sendNotModified() *types.RouteError {
+	w.Status = types.NotModified
+	w.Headers.Del("Content-Type")
+	w.Headers.Del("Content-Length")
+	return w.SendResponse(nil)
+}
+
 func (w *ResponseWriter) SendJSON(data any, status types.StatusCode) *types.RouteError {
 	body, err := json.Marshal(data)
 	if err != nil {
@@ -208,6 +326,18 @@ func (w *ResponseWriter) SendJSON(data any, status types.StatusCode) *types.Rout
 	return w.SendResponse(body)
 }
 
+// SendError sends a plain-text response with the given status code
+func (w *ResponseWriter) SendError(status types.StatusCode, message string) error {
+	w.Status = status
+	if !w.Headers.Has("Content-Type") {
+		w.Headers.Set("Content-Type", string(types.TextPlain))
+	}
+	if err := w.SendResponse([]byte(message)); err != nil {
+		return err
+	}
+	return nil
+}
+
 // SendBadRequest sends a 400 Bad Request response
 func (w *ResponseWriter) SendBadRequest(message string) error {
 	w.Status = types.BadRequest
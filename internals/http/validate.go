@@ -0,0 +1,180 @@
+package http
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	types "myserver/internals/type"
+)
+
+// Validate checks the validate tags of the struct v points to and returns one
+// FieldError per failing field. Rules are separated by commas:
+//
+//	required   the field is not its zero value
+//	min=N      numbers are >= N; strings, slices and maps have >= N elements
+//	max=N      numbers are <= N; strings, slices and maps have <= N elements
+//	email      the string is a bare e-mail address
+//	regex=RE   the string matches RE as a whole; must be the last rule since
+//	           RE may itself contain commas
+//
+// Empty optional fields skip the other rules. Nested and embedded structs
+// are validated too, their field names joined with a dot.
+func Validate(v any) []types.FieldError {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return validateStruct(rv, "", nil)
+}
+
+func validateStruct(v reflect.Value, prefix string, errs []types.FieldError) []types.FieldError {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			errs = validateStruct(fv, prefix, errs)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		if rules := field.Tag.Get("validate"); rules != "" {
+			if msg := checkRules(fv, rules); msg != "" {
+				errs = append(errs, types.FieldError{Field: name, Message: msg})
+				continue
+			}
+		}
+
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+			errs = validateStruct(fv, name+".", errs)
+		}
+	}
+	return errs
+}
+
+// fieldName is the name a client knows the field by: the first name given in
+// a json, form, query, param or header tag, else the Go name.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "query", "param", "header"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// checkRules returns why v breaks rules, or "" when it satisfies them.
+func checkRules(v reflect.Value, rules string) string {
+	required := false
+	for rule := range strings.SplitSeq(rules, ",") {
+		if rule == "required" {
+			required = true
+		}
+		if strings.HasPrefix(rule, "regex=") {
+			break
+		}
+	}
+	if v.IsZero() {
+		if required {
+			return "is required"
+		}
+		return ""
+	}
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	for rules != "" {
+		var rule string
+		if strings.HasPrefix(rules, "regex=") {
+			rule, rules = rules, ""
+		} else {
+			rule, rules, _ = strings.Cut(rules, ",")
+		}
+		name, arg, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				panic(fmt.Sprintf("http: invalid validate rule %q", rule))
+			}
+			n, unit := measure(v)
+			if unit != "" {
+				unit = " " + unit
+			}
+			switch {
+			case name == "min" && n < limit:
+				return fmt.Sprintf("must be at least %s%s", arg, unit)
+			case name == "max" && n > limit:
+				return fmt.Sprintf("must be at most %s%s", arg, unit)
+			}
+		case "email":
+			if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+				return "must be a valid e-mail address"
+			}
+		case "regex":
+			if !compileRule(arg).MatchString(v.String()) {
+				return "must match " + arg
+			}
+		default:
+			panic(fmt.Sprintf("http: unknown validate rule %q", rule))
+		}
+	}
+	return ""
+}
+
+// measure returns the value min and max compare against: the number itself,
+// or the length of strings (in characters), slices and maps along with its
+// unit.
+func measure(v reflect.Value) (float64, string) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), ""
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), "elements"
+	default:
+		panic(fmt.Sprintf("http: min/max on unsupported type %s", v.Type()))
+	}
+}
+
+// rules are compiled once per pattern and shared between requests
+var ruleCache sync.Map
+
+func compileRule(expr string) *regexp.Regexp {
+	if re, ok := ruleCache.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(`^(?:` + expr + `)$`)
+	ruleCache.Store(expr, re)
+	return re
+}
@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Reflector turns Go types into schemas the way encoding/json would encode
+// them. Named struct types are stored once in Components and referenced with
+// $ref, which also takes care of recursive types.
+type Reflector struct {
+	Components *Components
+	// names maps every struct type stored in Components to its schema name
+	names map[reflect.Type]string
+}
+
+func NewReflector() *Reflector {
+	return &Reflector{
+		Components: &Components{Schemas: make(map[string]*Schema)},
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// Schema returns the schema for the type of v. A nil v yields nil.
+func (r *Reflector) Schema(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+func (r *Reflector) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes []byte as base64
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name, ok := r.names[t]
+		if !ok {
+			// reserve the name before recursing so self references stop here
+			name = r.componentName(t)
+			r.names[t] = name
+			r.Components.Schemas[name] = &Schema{}
+			*r.Components.Schemas[name] = *r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// interfaces and anything else: any JSON value
+		return &Schema{}
+	}
+}
+
+// componentName picks a free schema name for t: its type name, or, when a
+// type from another package already took it, the name qualified by the last
+// element of its package path ("admin.User"), numbered if even that is taken.
+func (r *Reflector) componentName(t reflect.Type) string {
+	if r.names == nil {
+		r.names = make(map[reflect.Type]string)
+	}
+	name := t.Name()
+	if _, taken := r.Components.Schemas[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	qualified := pkg[strings.LastIndexByte(pkg, '/')+1:] + "." + t.Name()
+	name = qualified
+	for i := 2; ; i++ {
+		if _, taken := r.Components.Schemas[name]; !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s%d", qualified, i)
+	}
+}
+
+// structSchema lists the fields encoding/json would encode: exported, not
+// tagged "-", with embedded structs flattened. Fields without omitempty are
+// required.
+func (r *Reflector) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded := r.structSchema(ft)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = r.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	neturl "net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+type URL struct {
+	Href string `json:"href"`
+}
+
+type Node struct {
+	Value    int     `json:"value"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+type Embedded struct {
+	ID string `json:"id"`
+}
+
+type Item struct {
+	Embedded
+	Name    string    `json:"name"`
+	Note    string    `json:"note,omitempty"`
+	Created time.Time `json:"created"`
+	Data    []byte    `json:"data"`
+	Secret  string    `json:"-"`
+	hidden  string
+}
+
+func TestSchemaNameClash(t *testing.T) {
+	r := NewReflector()
+	local := r.Schema(URL{})
+	std := r.Schema(neturl.URL{})
+	again := r.Schema(&URL{})
+
+	if local.Ref != "#/components/schemas/URL" {
+		t.Errorf("local URL ref = %q", local.Ref)
+	}
+	if std.Ref != "#/components/schemas/url.URL" {
+		t.Errorf("net/url URL ref = %q", std.Ref)
+	}
+	if again.Ref != local.Ref {
+		t.Errorf("same type reflected twice: %q then %q", local.Ref, again.Ref)
+	}
+	if _, ok := r.Components.Schemas["URL"].Properties["href"]; !ok {
+		t.Errorf("URL schema was overwritten: %+v", r.Components.Schemas["URL"])
+	}
+	if _, ok := r.Components.Schemas["url.URL"].Properties["Host"]; !ok {
+		t.Errorf("url.URL schema = %+v", r.Components.Schemas["url.URL"])
+	}
+
+	// types declared in functions share both package and name
+	first := func() any { type Local struct{ A int }; return Local{} }()
+	second := func() any { type Local struct{ B int }; return Local{} }()
+	refs := []string{r.Schema(first).Ref, r.Schema(second).Ref}
+	if refs[0] == refs[1] {
+		t.Errorf("two distinct types share %q", refs[0])
+	}
+}
+
+func TestSchemaStruct(t *testing.T) {
+	r := NewReflector()
+	if ref := r.Schema(Item{}).Ref; ref != "#/components/schemas/Item" {
+		t.Fatalf("ref = %q", ref)
+	}
+	s := r.Components.Schemas["Item"]
+	var props []string
+	for name := range s.Properties {
+		props = append(props, name)
+	}
+	slices.Sort(props)
+	if want := []string{"created", "data", "id", "name", "note"}; !slices.Equal(props, want) {
+		t.Errorf("properties = %v, want %v", props, want)
+	}
+	required := slices.Sorted(slices.Values(s.Required))
+	if want := []string{"created", "data", "id", "name"}; !slices.Equal(required, want) {
+		t.Errorf("required = %v, want %v", required, want)
+	}
+	if p := s.Properties["created"]; p.Type != "string" || p.Format != "date-time" {
+		t.Errorf("created = %+v", p)
+	}
+	if p := s.Properties["data"]; p.Type != "string" || p.Format != "byte" {
+		t.Errorf("data = %+v", p)
+	}
+}
+
+func TestSchemaRecursive(t *testing.T) {
+	r := NewReflector()
+	r.Schema(Node{})
+	children := r.Components.Schemas["Node"].Properties["children"]
+	if children.Type != "array" || children.Items.Ref != "#/components/schemas/Node" {
+		t.Errorf("children = %+v", children)
+	}
+}
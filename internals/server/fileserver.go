@@ -0,0 +1,229 @@
+package server
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	neturl "net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	http "myserver/internals/http"
+	types "myserver/internals/type"
+	url "myserver/internals/utils"
+)
+
+// FileOptions tune FileServer. The zero value serves "index.html" for
+// directories and refuses to list directories without one.
+type FileOptions struct {
+	// Index is the file served for a directory, "index.html" when empty.
+	Index string
+	// Listing lists directories without an index file, as HTML or as JSON
+	// depending on the Accept header.
+	Listing bool
+}
+
+// FileServer serves the files below the directory root. Register it on a
+// catch-all, whose value is the file to serve:
+//
+//	server.Handle(types.GET, "/static/{path...}", server.FileServer("static", server.FileOptions{}))
+//
+// On a route without a catch-all the request path, below any Mount prefix,
+// is used instead. Lookups can not leave root: ".." segments and NUL bytes
+// are refused, and symbolic links pointing outside root are not followed.
+// Missing files are answered with 404, anything that exists but may not be
+// served with 403.
+func FileServer(root string, opts FileOptions) Handler {
+	serve := serveFS(opts)
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		dir, err := os.OpenRoot(root)
+		if err != nil {
+			return fileError(err)
+		}
+		defer dir.Close()
+		return serve(w, r, dir.FS())
+	}
+}
+
+// FileServerFS is like FileServer for any fs.FS, such as an embed.FS that
+// ships the assets inside the binary:
+//
+//	//go:embed static
+//	var assets embed.FS
+//
+//	sub, _ := fs.Sub(assets, "static")
+//	server.Handle(types.GET, "/{path...}", server.FileServerFS(sub, server.FileOptions{}))
+//
+// Sizes and modification times come from the files' fs.FileInfo. Files
+// without a modification time, like embedded ones, are tagged with a hash
+// of their content instead, computed once: they are assumed not to change
+// while the server runs.
+func FileServerFS(fsys fs.FS, opts FileOptions) Handler {
+	serve := serveFS(opts)
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		return serve(w, r, fsys)
+	}
+}
+
+func serveFS(opts FileOptions) func(w *http.ResponseWriter, r *http.Request, fsys fs.FS) *types.RouteError {
+	index := cmp.Or(opts.Index, "index.html")
+	var etags sync.Map // name -> contentTag, for files without modtime
+	return func(w *http.ResponseWriter, r *http.Request, fsys fs.FS) *types.RouteError {
+		name, routeErr := fileName(r)
+		if routeErr != nil {
+			return routeErr
+		}
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return fileError(err)
+		}
+		if info.IsDir() {
+			indexName := path.Join(name, index)
+			if indexInfo, err := fs.Stat(fsys, indexName); err == nil && indexInfo.Mode().IsRegular() {
+				name, info = indexName, indexInfo
+			} else if opts.Listing {
+				return listDirectory(w, r, fsys, name)
+			} else {
+				return &types.RouteError{Code: types.Forbidden, Message: "Directory listing is disabled"}
+			}
+		}
+		if !info.Mode().IsRegular() {
+			return &types.RouteError{Code: types.Forbidden, Message: "Forbidden"}
+		}
+
+		if info.ModTime().IsZero() {
+			etag, err := contentETag(&etags, fsys, name, info.Size())
+			if err != nil {
+				return fileError(err)
+			}
+			w.Headers.Set("ETag", etag)
+		}
+		w.Status = types.OK
+		return w.SendFileFS(fsys, name)
+	}
+}
+
+type contentTag struct {
+	size int64
+	etag string
+}
+
+// contentETag returns a strong ETag made from a hash of the file's content,
+// cached by name and size.
+func contentETag(cache *sync.Map, fsys fs.FS, name string, size int64) (string, error) {
+	if tag, ok := cache.Load(name); ok && tag.(contentTag).size == size {
+		return tag.(contentTag).etag, nil
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	etag := fmt.Sprintf(`"%x"`, h.Sum(nil)[:16])
+	cache.Store(name, contentTag{size, etag})
+	return etag, nil
+}
+
+// fileName returns the slash-separated name to look up below the root: the
+// value of the route's catch-all or else the request path, "." for the root
+// itself.
+func fileName(r *http.Request) (string, *types.RouteError) {
+	name := cmp.Or(r.RoutePath, r.URL.Path)
+	pattern := strings.TrimSuffix(r.Pattern, "/")
+	if url.IsCatchAll(pattern[strings.LastIndexByte(pattern, '/')+1:]) {
+		// a catch-all is the last segment, so it is captured last
+		name = r.Params[len(r.Params)-1].Value
+	}
+	if strings.IndexByte(name, 0) != -1 {
+		return "", &types.RouteError{Code: types.BadRequest, Message: "Invalid file name"}
+	}
+	for seg := range strings.SplitSeq(name, "/") {
+		if seg == ".." {
+			return "", &types.RouteError{Code: types.Forbidden, Message: "Forbidden"}
+		}
+	}
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, nil
+}
+
+// fileError maps a failed lookup to 404 when nothing is there and to 403
+// otherwise, including links that escape the root.
+func fileError(err error) *types.RouteError {
+	if errors.Is(err, fs.ErrNotExist) {
+		return &types.RouteError{Code: types.NotFound, Message: "File not found"}
+	}
+	return &types.RouteError{Code: types.Forbidden, Message: "Forbidden"}
+}
+
+// dirEntry is one line of a JSON directory listing.
+type dirEntry struct {
+	Name    string    `json:"name"`
+	Dir     bool      `json:"dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime,omitzero"`
+}
+
+// listDirectory answers with the entries of the directory name, sorted by
+// name (as fs.ReadDir returns them), as HTML unless the client prefers JSON.
+func listDirectory(w *http.ResponseWriter, r *http.Request, fsys fs.FS, name string) *types.RouteError {
+	infos, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return fileError(err)
+	}
+
+	entries := make([]dirEntry, 0, len(infos))
+	for _, e := range infos {
+		entry := dirEntry{Name: e.Name(), Dir: e.IsDir()}
+		if info, err := e.Info(); err == nil {
+			entry.Size, entry.ModTime = info.Size(), info.ModTime()
+		}
+		entries = append(entries, entry)
+	}
+
+	w.Headers.Add("Vary", "Accept")
+	accept := strings.Join(r.Headers.Values("Accept"), ",")
+	if http.Negotiate(accept, "text/html", "application/json") == "application/json" {
+		return w.SendJSON(entries, types.OK)
+	}
+
+	// links are absolute so they work with or without a trailing slash
+	base := r.URL.Path
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	var b strings.Builder
+	title := html.EscapeString("Index of " + base)
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head>\n<body><h1>%s</h1>\n<ul>\n", title, title)
+	if name != "." {
+		fmt.Fprintf(&b, "<li><a href=\"%s\">../</a></li>\n", html.EscapeString(path.Dir(strings.TrimSuffix(base, "/"))+"/"))
+	}
+	for _, e := range entries {
+		label := e.Name
+		if e.Dir {
+			label += "/"
+		}
+		href := base + neturl.PathEscape(e.Name)
+		if e.Dir {
+			href += "/"
+		}
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(href), html.EscapeString(label))
+	}
+	b.WriteString("</ul></body></html>\n")
+	w.Headers.Set("Content-Type", string(types.TextHTML))
+	w.Status = types.OK
+	return w.SendResponse([]byte(b.String()))
+}
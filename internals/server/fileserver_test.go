@@ -0,0 +1,150 @@
+package server
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	types "myserver/internals/type"
+	url "myserver/internals/utils"
+)
+
+const secret = "top secret"
+
+// traversals are targets trying to reach "secret", which lives next to the
+// served directory.
+var traversals = []string{
+	"/../secret",
+	"/../../secret",
+	"/a/../../secret",
+	"/%2e%2e/secret",
+	"/%2E%2E/secret",
+	"/.%2e/secret",
+	"/a/%2F..%2F..%2Fsecret",
+	"/..%2fsecret",
+	"/..%5csecret",
+	"/a/..%2F..%2Fsecret",
+}
+
+func TestFileServerTraversal(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "public")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret"), []byte(secret), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "hello.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := NewRouter()
+	rt.Handle(types.GET, "/{file...}", FileServer(root, FileOptions{}))
+
+	if resp := serve(t, rt.Serve, types.GET, "/hello.txt"); resp.StatusCode != 200 || resp.body != "hello" {
+		t.Fatalf("GET /hello.txt = %d %q, want 200 %q", resp.StatusCode, resp.body, "hello")
+	}
+	for _, target := range append(traversals, "/link") {
+		resp := serve(t, rt.Serve, types.GET, target)
+		if resp.StatusCode == 200 || strings.Contains(resp.body, secret) {
+			t.Errorf("GET %s = %d %q, escaped the root", target, resp.StatusCode, resp.body)
+		}
+	}
+}
+
+func TestFileServerFSTraversal(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/hello.txt": {Data: []byte("hello")},
+		"secret":           {Data: []byte(secret)},
+	}
+	public, err := fs.Sub(fsys, "public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := NewRouter()
+	rt.Handle(types.GET, "/static/{file...}", FileServerFS(public, FileOptions{}))
+
+	if resp := serve(t, rt.Serve, types.GET, "/static/hello.txt"); resp.StatusCode != 200 || resp.body != "hello" {
+		t.Fatalf("GET /static/hello.txt = %d %q, want 200 %q", resp.StatusCode, resp.body, "hello")
+	}
+	for _, target := range traversals {
+		target = "/static" + target
+		resp := serve(t, rt.Serve, types.GET, target)
+		if strings.Contains(resp.body, secret) {
+			t.Errorf("GET %s = %d %q, escaped the served directory", target, resp.StatusCode, resp.body)
+		}
+	}
+}
+
+func TestFileServerRouteParams(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("home")},
+		"acme":          {Data: []byte("not the index")},
+		"v/2/notes.txt": {Data: []byte("notes")},
+		"a.txt":         {Data: []byte("a")},
+	}
+	files := FileServerFS(fsys, FileOptions{})
+
+	sub := NewRouter()
+	sub.Handle(types.GET, "/", files)
+	sub.Handle(types.GET, "/docs/{name...}", files)
+	rt := NewRouter()
+	rt.Mount("/orgs/{org}", sub)
+	rt.Handle(types.GET, "/v/{version}/notes.txt", files)
+
+	tests := []struct {
+		target string
+		status int
+		body   string
+	}{
+		// the mount's {org} is not the file to serve
+		{"/orgs/acme/", 200, "home"},
+		{"/orgs/acme", 200, "home"},
+		// without a catch-all the request path is the file name
+		{"/v/2/notes.txt", 200, "notes"},
+		{"/v/3/notes.txt", 404, ""},
+		// with one, its value is
+		{"/orgs/acme/docs/a.txt", 200, "a"},
+	}
+	for _, tt := range tests {
+		resp := serve(t, rt.Serve, types.GET, tt.target)
+		if resp.StatusCode != tt.status || (tt.status == 200 && resp.body != tt.body) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.target, resp.StatusCode, resp.body, tt.status, tt.body)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		file   string
+		want   string
+		status types.StatusCode
+	}{
+		{file: "", want: "."},
+		{file: "a/b.txt", want: "a/b.txt"},
+		{file: "a//b.txt", want: "a/b.txt"},
+		{file: "a/./b.txt", want: "a/b.txt"},
+		{file: "../secret", status: types.Forbidden},
+		{file: "a/../../secret", status: types.Forbidden},
+		{file: "a\x00b", status: types.BadRequest},
+	}
+	for _, tt := range tests {
+		r := parseRequest(t, "/")
+		r.Pattern = "/{file...}"
+		r.Params = url.Params{{Key: "file", Value: tt.file}}
+		got, routeErr := fileName(r)
+		switch {
+		case tt.status != 0 && (routeErr == nil || routeErr.Code != tt.status):
+			t.Errorf("fileName(%q) error = %v, want %d", tt.file, routeErr, tt.status)
+		case tt.status == 0 && (routeErr != nil || got != tt.want):
+			t.Errorf("fileName(%q) = %q, %v; want %q", tt.file, got, routeErr, tt.want)
+		}
+	}
+}
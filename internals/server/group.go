@@ -0,0 +1,72 @@
+package server
+
+import (
+	"strings"
+
+	types "myserver/internals/type"
+)
+
+// Group registers routes under a common path prefix with its own middleware
+// stack. Group middleware runs after the router-wide middleware and before
+// any per-route middleware, and only for the group's routes: unknown paths
+// and routes outside the group never see it.
+//
+// Middleware is attached when a route is registered, so Use only affects
+// routes added after it.
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares *MiddlewareChain
+}
+
+// Group returns a sub-router for routes under prefix, e.g.
+//
+//	api := server.Group("/api", authMiddleware)
+//	api.Handle(types.GET, "/info", handleInfo) // GET /api/info
+func (rt *Router) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		router:      rt,
+		prefix:      joinPath("", prefix),
+		middlewares: NewMiddlewareChain().With(middlewares...),
+	}
+}
+
+// Group nests a sub-group: prefixes are concatenated and the parent's
+// middleware runs before the child's.
+func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		router:      g.router,
+		prefix:      joinPath(g.prefix, prefix),
+		middlewares: g.middlewares.With(middlewares...),
+	}
+}
+
+func (g *Group) Use(middleware Middleware) {
+	g.middlewares.Use(middleware)
+}
+
+// Handle registers handler for method and prefix+path, wrapped in the
+// group's middleware and then the route's own.
+func (g *Group) Handle(method types.Method, path string, handler Handler, middlewares ...Middleware) *Route {
+	chain := g.middlewares.With(middlewares...)
+	return g.router.handle(method, joinPath(g.prefix, path), handler, g.prefix, chain.middlewares)
+}
+
+// Mount attaches sub under prefix+path; see Router.Mount.
+func (g *Group) Mount(path string, sub *Router) {
+	g.router.Mount(joinPath(g.prefix, path), sub, g.middlewares.middlewares...)
+}
+
+// joinPath joins a group prefix and a route path with exactly one slash.
+func joinPath(prefix, path string) string {
+	prefix = strings.Trim(prefix, "/")
+	path = strings.Trim(path, "/")
+	switch {
+	case prefix == "":
+		return "/" + path
+	case path == "":
+		return "/" + prefix
+	default:
+		return "/" + prefix + "/" + path
+	}
+}
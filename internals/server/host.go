@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net"
+	"strings"
+)
+
+// Host returns the router serving requests for pattern, creating it on first
+// use. pattern is a host name ("api.example.test") or a wildcard
+// ("*.example.test") matching any subdomain of example.test. Requests for
+// hosts without a router fall back to the server's own routes.
+//
+// Server-wide middleware (Server.Use) runs for every host.
+func (s *Server) Host(pattern string) *Router {
+	pattern = strings.ToLower(pattern)
+	if rt, ok := s.hosts[pattern]; ok {
+		return rt
+	}
+	rt := NewRouter()
+	s.hosts[pattern] = rt
+	return rt
+}
+
+// routerFor picks the router for a Host value: an exact match first, then
+// the most specific wildcard, then the default routes.
+func (s *Server) routerFor(host string) *Router {
+	if len(s.hosts) == 0 {
+		return s.Router
+	}
+	host = strings.ToLower(stripPort(host))
+	if rt, ok := s.hosts[host]; ok {
+		return rt
+	}
+	// walk up the labels: a.b.example.test tries *.b.example.test,
+	// then *.example.test, then *.test
+	for i := strings.IndexByte(host, '.'); i != -1; {
+		if rt, ok := s.hosts["*"+host[i:]]; ok {
+			return rt
+		}
+		next := strings.IndexByte(host[i+1:], '.')
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	return s.Router
+}
+
+// stripPort removes a trailing ":port", keeping IPv6 literals intact.
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"testing"
+
+	types "myserver/internals/type"
+)
+
+func TestRouterFor(t *testing.T) {
+	s := NewServer(0)
+	routers := map[string]*Router{
+		"default": s.Router,
+		"api":     s.Host("API.example.test"),
+		"any":     s.Host("*.example.test"),
+		"b":       s.Host("*.b.example.test"),
+		"ipv6":    s.Host("::1"),
+	}
+	names := make(map[*Router]string)
+	for name, rt := range routers {
+		names[rt] = name
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.example.test", "api"},
+		{"API.example.test:8080", "api"},
+		{"www.example.test", "any"},
+		{"a.www.example.test", "any"},
+		// the most specific wildcard wins
+		{"b.example.test", "any"},
+		{"a.b.example.test", "b"},
+		{"x.a.b.example.test", "b"},
+		// a wildcard only covers subdomains
+		{"example.test", "default"},
+		{"example.test:8080", "default"},
+		{"other.test", "default"},
+		{"localhost", "default"},
+		{"[::1]:8080", "ipv6"},
+		{"[::1]", "ipv6"},
+		{"", "default"},
+	}
+	for _, tt := range tests {
+		if got := names[s.routerFor(tt.host)]; got != tt.want {
+			t.Errorf("routerFor(%q) = %s, want %s", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestServerDispatchByHost(t *testing.T) {
+	s := NewServer(0)
+	s.Handle(types.GET, "/", text("default"))
+	s.Host("api.example.test").Handle(types.GET, "/", text("api"))
+
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{"/", "default"},
+		// the authority of an absolute-form target is the host
+		{"http://API.example.test:8080/", "api"},
+		{"http://example.test/", "default"},
+	}
+	for _, tt := range tests {
+		if resp := serve(t, s.dispatch, types.GET, tt.target); resp.body != tt.body {
+			t.Errorf("GET %s = %q, want %q", tt.target, resp.body, tt.body)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"cmp"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	http "myserver/internals/http"
+	types "myserver/internals/type"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Host        string       `json:"host,omitempty"`
+	Method      types.Method `json:"method"`
+	Pattern     string       `json:"pattern"`
+	Name        string       `json:"name,omitempty"`
+	Group       string       `json:"group,omitempty"`
+	Middlewares []string     `json:"middlewares,omitempty"`
+}
+
+// Routes lists the routes of rt and of every router mounted on it (with the
+// mount prefix in front), sorted by pattern then method. Middlewares are
+// listed outermost first: the router's own, then the group's and the route's.
+// Routes of a mounted router also list the middlewares given to Mount,
+// between the outer router's and the mounted router's own.
+func (rt *Router) Routes() []RouteInfo {
+	infos := rt.routeInfos("", nil)
+	sortRoutes(infos)
+	return infos
+}
+
+// Routes lists the routes of every host, the default host first. Server-wide
+// middleware is included in each route's list.
+func (s *Server) Routes() []RouteInfo {
+	infos := s.Router.routeInfos("", s.middlewares.middlewares)
+	for host, rt := range s.hosts {
+		for _, info := range rt.routeInfos("", s.middlewares.middlewares) {
+			info.Host = host
+			infos = append(infos, info)
+		}
+	}
+	sortRoutes(infos)
+	return infos
+}
+
+func (rt *Router) routeInfos(prefix string, outer []Middleware) []RouteInfo {
+	chain := slices.Concat(outer, rt.middlewares.middlewares)
+	var infos []RouteInfo
+	for _, route := range rt.registered {
+		info := RouteInfo{
+			Method:      route.Method,
+			Pattern:     route.Pattern,
+			Name:        route.name,
+			Group:       route.group,
+			Middlewares: middlewareNames(slices.Concat(chain, route.middlewares)),
+		}
+		if prefix != "" {
+			info.Pattern = joinPath(prefix, route.Pattern)
+			info.Group = joinPath(prefix, route.group)
+		}
+		infos = append(infos, info)
+	}
+	for _, m := range rt.mounts {
+		infos = append(infos, m.sub.routeInfos(joinPath(prefix, m.prefix), slices.Concat(chain, m.middlewares))...)
+	}
+	return infos
+}
+
+func sortRoutes(infos []RouteInfo) {
+	slices.SortFunc(infos, func(a, b RouteInfo) int {
+		return cmp.Or(
+			cmp.Compare(a.Host, b.Host),
+			cmp.Compare(a.Pattern, b.Pattern),
+			cmp.Compare(a.Method, b.Method),
+		)
+	})
+}
+
+// middlewareNames returns the function names of middlewares without their
+// import path, e.g. "main.LoggingMiddleware".
+func middlewareNames(middlewares []Middleware) []string {
+	names := make([]string, 0, len(middlewares))
+	for _, m := range middlewares {
+		name := "?"
+		if fn := runtime.FuncForPC(reflect.ValueOf(m).Pointer()); fn != nil {
+			name = fn.Name()
+			name = name[strings.LastIndexByte(name, '/')+1:]
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// RoutesHandler serves the route table as JSON, for a debug endpoint:
+//
+//	server.Handle(types.GET, "/debug/routes", server.RoutesHandler())
+func (s *Server) RoutesHandler() Handler {
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		return w.SendJSON(s.Routes(), types.OK)
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	types "myserver/internals/type"
+)
+
+func outerMiddleware(next Handler) Handler { return next }
+func mountMiddleware(next Handler) Handler { return next }
+func innerMiddleware(next Handler) Handler { return next }
+
+func TestRoutesMiddlewares(t *testing.T) {
+	admin := NewRouter()
+	admin.Use(innerMiddleware)
+	admin.Handle(types.GET, "/users", echo)
+
+	rt := NewRouter()
+	rt.Use(outerMiddleware)
+	rt.Mount("/admin", admin, mountMiddleware)
+	rt.Handle(types.GET, "/", echo)
+
+	routes := rt.Routes()
+	want := []RouteInfo{
+		{Method: types.GET, Pattern: "/", Middlewares: []string{"server.outerMiddleware"}},
+		{Method: types.GET, Pattern: "/admin/users", Group: "/admin", Middlewares: []string{
+			"server.outerMiddleware", "server.mountMiddleware", "server.innerMiddleware",
+		}},
+	}
+	if len(routes) != len(want) {
+		t.Fatalf("Routes() = %+v, want %+v", routes, want)
+	}
+	for i := range want {
+		got := routes[i]
+		if got.Method != want[i].Method || got.Pattern != want[i].Pattern || got.Group != want[i].Group ||
+			!slices.Equal(got.Middlewares, want[i].Middlewares) {
+			t.Errorf("Routes()[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package server
 
+import "slices"
+
 // Request → Middleware1 → Middleware2 → Middleware3 → Final Handler → Response
 
 type Middleware func(next Handler) Handler
@@ -17,11 +19,20 @@ func (mc *MiddlewareChain) Use(middleware Middleware) {
 	mc.middlewares = append(mc.middlewares, middleware)
 }
 
-// Apply applies all middlewares to a handler in reverse order
+// With returns a new chain running mc's middlewares followed by extra.
+// mc itself is left untouched.
+func (mc *MiddlewareChain) With(extra ...Middleware) *MiddlewareChain {
+	return &MiddlewareChain{
+		middlewares: slices.Concat(mc.middlewares, extra),
+	}
+}
+
+// Apply applies all middlewares to a handler in reverse order, so the first
+// one registered is the outermost and runs first:
 // M1 → M2 → M3 → FinalHandler
 func (mc *MiddlewareChain) Apply(finalHandler Handler) Handler {
 	handler := finalHandler
-	for _, m := range mc.middlewares {
+	for _, m := range slices.Backward(mc.middlewares) {
 		handler = m(handler)
 	}
 	return handler
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	http "myserver/internals/http"
+	types "myserver/internals/type"
+)
+
+// record returns a middleware that appends name to trace before and
+// "/"+name after calling the next handler.
+func record(trace *[]string, name string) Middleware {
+	return func(next Handler) Handler {
+		return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+			*trace = append(*trace, name)
+			err := next(w, r)
+			*trace = append(*trace, "/"+name)
+			return err
+		}
+	}
+}
+
+func TestMiddlewareChainOrder(t *testing.T) {
+	var trace []string
+	chain := NewMiddlewareChain()
+	chain.Use(record(&trace, "m1"))
+	chain.Use(record(&trace, "m2"))
+	chain = chain.With(record(&trace, "m3"))
+
+	handler := chain.Apply(func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		trace = append(trace, "handler")
+		return nil
+	})
+	if err := handler(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"m1", "m2", "m3", "handler", "/m3", "/m2", "/m1"}
+	if !slices.Equal(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestMiddlewareWithLeavesChainAlone(t *testing.T) {
+	var trace []string
+	chain := NewMiddlewareChain()
+	chain.Use(record(&trace, "m1"))
+	_ = chain.With(record(&trace, "extra"))
+
+	_ = chain.Apply(func(w *http.ResponseWriter, r *http.Request) *types.RouteError { return nil })(nil, nil)
+	if want := []string{"m1", "/m1"}; !slices.Equal(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestMiddlewareOrderAcrossLayers(t *testing.T) {
+	var trace []string
+	rt := NewRouter()
+	rt.Use(record(&trace, "router"))
+	api := rt.Group("/api", record(&trace, "group"))
+	api.Handle(types.GET, "/ping", text("pong"), record(&trace, "route"))
+
+	resp := serve(t, rt.Serve, types.GET, "/api/ping")
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /api/ping = %d", resp.StatusCode)
+	}
+	want := []string{"router", "group", "route", "/route", "/group", "/router"}
+	if !slices.Equal(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+
+	http "myserver/internals/http"
+	"myserver/internals/openapi"
+	types "myserver/internals/type"
+	url "myserver/internals/utils"
+)
+
+// routeDoc is the optional OpenAPI metadata of a route.
+type routeDoc struct {
+	summary     string
+	description string
+	tags        []string
+	request     any
+	responses   map[types.StatusCode]any
+	params      map[string]string
+}
+
+// Summary sets the one-line summary of the operation.
+func (r *Route) Summary(summary string) *Route {
+	r.doc.summary = summary
+	return r
+}
+
+// Description sets the longer description of the operation.
+func (r *Route) Description(description string) *Route {
+	r.doc.description = description
+	return r
+}
+
+// Tags groups the operation in the generated document. Routes registered
+// through a group are tagged with the group prefix by default.
+func (r *Route) Tags(tags ...string) *Route {
+	r.doc.tags = append(r.doc.tags, tags...)
+	return r
+}
+
+// Request documents the JSON request body with a value of its Go type:
+//
+//	server.Handle(types.POST, "/users", create).Request(User{})
+func (r *Route) Request(body any) *Route {
+	r.doc.request = body
+	return r
+}
+
+// Response documents the JSON body sent with status. A nil body documents a
+// response without content.
+func (r *Route) Response(status types.StatusCode, body any) *Route {
+	if r.doc.responses == nil {
+		r.doc.responses = make(map[types.StatusCode]any)
+	}
+	r.doc.responses[status] = body
+	return r
+}
+
+// Param describes a path parameter of the pattern.
+func (r *Route) Param(name, description string) *Route {
+	if r.doc.params == nil {
+		r.doc.params = make(map[string]string)
+	}
+	r.doc.params[name] = description
+	return r
+}
+
+// OpenAPI builds an OpenAPI 3.1 document from the routes of rt and of every
+// router mounted on it. Path parameters come from the patterns themselves,
+// with constraints turned into schemas; optional segments produce one path
+// per variant, and a catch-all is documented as a single parameter.
+// Body schemas are reflected from the Go types given to Request and Response.
+func (rt *Router) OpenAPI(info openapi.Info) *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info:    info,
+		Paths:   make(map[string]*openapi.PathItem),
+	}
+	reflector := openapi.NewReflector()
+	rt.eachRoute("", func(prefix string, route *Route) {
+		pattern := route.Pattern
+		if prefix != "" {
+			pattern = joinPath(prefix, pattern)
+		}
+		method := strings.ToLower(string(route.Method))
+		for _, variant := range expandOptional(strings.Split(strings.Trim(pattern, "/"), "/")) {
+			path, params := openAPIPath(variant, route.doc.params)
+			item, ok := doc.Paths[path]
+			if !ok {
+				item = &openapi.PathItem{}
+				doc.Paths[path] = item
+			}
+			op := route.operation(reflector, prefix)
+			op.Parameters = params
+			(*item)[method] = op
+		}
+	})
+	if len(reflector.Components.Schemas) > 0 {
+		doc.Components = reflector.Components
+	}
+	return doc
+}
+
+// eachRoute calls fn with every registered route and the prefix it is mounted
+// under, the mounted routers after rt's own routes.
+func (rt *Router) eachRoute(prefix string, fn func(prefix string, route *Route)) {
+	for _, route := range rt.registered {
+		fn(prefix, route)
+	}
+	for _, m := range rt.mounts {
+		m.sub.eachRoute(joinPath(prefix, m.prefix), fn)
+	}
+}
+
+func (r *Route) operation(reflector *openapi.Reflector, prefix string) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationID: r.name,
+		Summary:     r.doc.summary,
+		Description: r.doc.description,
+		Tags:        r.doc.tags,
+		Responses:   make(map[string]*openapi.Response),
+	}
+	if op.Tags == nil && r.group != "" {
+		op.Tags = []string{strings.Trim(joinPath(prefix, r.group), "/")}
+	}
+	if r.doc.request != nil {
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  jsonContent(reflector.Schema(r.doc.request)),
+		}
+	}
+	for status, body := range r.doc.responses {
+		resp := &openapi.Response{Description: cmp.Or(types.StatusText[status], "Response")}
+		if body != nil {
+			resp.Content = jsonContent(reflector.Schema(body))
+		}
+		op.Responses[strconv.Itoa(int(status))] = resp
+	}
+	// an operation must document at least one response
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &openapi.Response{Description: "Response"}
+	}
+	return op
+}
+
+func jsonContent(schema *openapi.Schema) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{"application/json": {Schema: schema}}
+}
+
+// openAPIPath turns the segments of a pattern into an OpenAPI path template,
+// "{id:int}" becoming "{id}", and lists its parameters.
+func openAPIPath(segments []string, descriptions map[string]string) (string, []openapi.Parameter) {
+	var b strings.Builder
+	var params []openapi.Parameter
+	for _, seg := range segments {
+		if seg == "" {
+			continue
+		}
+		b.WriteByte('/')
+		if !url.IsParam(seg) && !url.IsCatchAll(seg) {
+			b.WriteString(seg)
+			continue
+		}
+		name, constraint, _ := url.ParseParam(seg)
+		b.WriteString("{" + name + "}")
+		params = append(params, openapi.Parameter{
+			Name:        name,
+			In:          "path",
+			Description: descriptions[name],
+			Required:    true,
+			Schema:      constraintSchema(constraint),
+		})
+	}
+	if b.Len() == 0 {
+		return "/", nil
+	}
+	return b.String(), params
+}
+
+// constraintSchema is the schema of a path parameter with the given
+// constraint: the built-in ones map to types, anything else is a pattern.
+func constraintSchema(constraint string) *openapi.Schema {
+	switch constraint {
+	case "":
+		return &openapi.Schema{Type: "string"}
+	case "int":
+		return &openapi.Schema{Type: "integer"}
+	case "bool":
+		return &openapi.Schema{Type: "boolean"}
+	case "uuid":
+		return &openapi.Schema{Type: "string", Format: "uuid"}
+	default:
+		return &openapi.Schema{Type: "string", Pattern: "^(?:" + constraint + ")$"}
+	}
+}
+
+// ServeOpenAPI registers a GET route at path serving the document of rt.
+// The document is built on every request, so routes registered afterwards
+// are included.
+func (rt *Router) ServeOpenAPI(path string, info openapi.Info) *Route {
+	return rt.Handle(types.GET, path, func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		return w.SendJSON(rt.OpenAPI(info), types.OK)
+	})
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
 	http "myserver/internals/http"
@@ -9,53 +11,219 @@ import (
 )
 
 type Handler func(w *http.ResponseWriter, r *http.Request) *types.RouteError
-type Routes map[types.Method]map[string]Handler
 
-func (s *Server) Handle(method types.Method, path string, handler Handler) {
+// Route is a registered method + pattern. Handle returns it so optional
+// details can be chained onto the registration:
+//
+//	server.Handle(types.GET, "/search/{id}", Search).Name("search")
+type Route struct {
+	Method  types.Method
+	Pattern string
+	name    string
+	handler Handler
+	router  *Router
+	// group prefix and the group + route middleware, for introspection
+	group       string
+	middlewares []Middleware
+	// optional documentation for the OpenAPI document
+	doc routeDoc
+	// parameter constraints as compiled by the tree, for URLFor
+	matchers matchers
+}
 
-	if s.routes[method] == nil {
-		s.routes[method] = make(map[string]Handler)
+// Name registers the route under name for URLFor. Names are unique per
+// router; reusing one panics.
+func (r *Route) Name(name string) *Route {
+	if existing, ok := r.router.names[name]; ok {
+		panic(fmt.Sprintf("server: route name %q already used by %s %s", name, existing.Method, existing.Pattern))
 	}
+	r.name = name
+	r.router.names[name] = r
+	return r
+}
 
-	s.routes[method][path] = handler
+// Handle registers handler for method and path. The route tree is updated
+// right away, so lookups never re-parse patterns. Middlewares given here only
+// wrap this route and run after the router-wide ones.
+func (rt *Router) Handle(method types.Method, path string, handler Handler, middlewares ...Middleware) *Route {
+	return rt.handle(method, path, handler, "", middlewares)
 }
 
-// TODO: make sure the logic is working..
-func (s *Server) FindRoute(path string, method types.Method) (Handler, url.Params) {
+func (rt *Router) handle(method types.Method, path string, handler Handler, group string, middlewares []Middleware) *Route {
+	if len(middlewares) > 0 {
+		handler = NewMiddlewareChain().With(middlewares...).Apply(handler)
+	}
+	route := &Route{
+		Method:      method,
+		Pattern:     path,
+		handler:     handler,
+		router:      rt,
+		group:       group,
+		middlewares: middlewares,
+	}
+	ends, matchers := rt.routes.insert(path)
+	route.matchers = matchers
+	for _, n := range ends {
+		if existing, ok := n.routes[method]; ok {
+			panic(conflict(path, existing.Pattern, fmt.Sprintf("both handle %s on the same paths", method)))
+		}
+		if n.routes == nil {
+			n.routes = make(map[types.Method]*Route)
+		}
+		n.routes[method] = route
+	}
+	rt.maxParams = max(rt.maxParams, countParams(path))
+	rt.registered = append(rt.registered, route)
+	return route
+}
 
-	methodRoutes, ok := s.routes[method]
-	if !ok {
-		return http.MethodNotAllowedHandler, nil
+// countParams is the number of parameters a pattern captures: one per
+// "{name}" or catch-all segment. Braces inside a constraint such as
+// "{id:[0-9]{3}}" do not count.
+func countParams(pattern string) int {
+	count := 0
+	for seg := range strings.SplitSeq(pattern, "/") {
+		if url.IsParam(seg) || url.IsCatchAll(seg) {
+			count++
+		}
 	}
+	return count
+}
 
-	url.CleanURL(&path)
+// FindRoute returns the handler registered for path and method along with
+// the captured route parameters. path is an origin-form target, escapes and
+// query included. An unknown or invalid path yields a 404 handler; a path
+// registered under other methods only yields a 405 handler that lists them
+// in the Allow header.
+func (rt *Router) FindRoute(path string, method types.Method) (Handler, url.Params) {
+	target, err := url.ParseURL(path)
+	if err != nil {
+		return http.NotFoundHandler, nil
+	}
+	n, params := rt.routes.lookup(target.Path, make(url.Params, 0, rt.maxParams))
+	if n == nil {
+		return http.NotFoundHandler, nil
+	}
+	if handler := n.handler(method); handler != nil {
+		return handler, params
+	}
+	return methodNotAllowed(rt.allowedMethods(n)), nil
+}
 
-	reqSegments := strings.Split(strings.Trim(path, "/"), "/")
+// resolve picks the handler for a request. On top of the registered routes
+// the server answers a few methods itself: HEAD falls back to the GET route,
+// OPTIONS lists the allowed methods and TRACE is refused unless enabled.
+//
+// Parameters are captured into params, which the caller may reuse between
+// requests to avoid allocating.
+func (rt *Router) resolve(req *http.Request, params url.Params) (Handler, url.Params) {
+	method := req.RequestLine.Method
+	path := req.URL.Path
+	if req.RoutePath != "" {
+		path = req.RoutePath
+	}
+	req.Pattern = ""
 
-	for routePath, handler := range methodRoutes {
-		routeSegments := strings.Split(strings.Trim(routePath, "/"), "/")
-		if len(routeSegments) != len(reqSegments) {
-			continue
+	if path == "*" {
+		if method == types.OPTIONS {
+			return optionsHandler(rt.allowedMethods(nil)), nil
 		}
+		return http.NotFoundHandler, nil
+	}
 
-		params := make(url.Params)
-		matched := true
-
-		for i, seg := range reqSegments {
-			rSeg := routeSegments[i]
-			if url.IsParam(rSeg) {
-				paramName := rSeg[1 : len(rSeg)-1]
-				params[paramName] = seg
-			} else if rSeg != seg {
-				matched = false
-				break
-			}
-		}
+	n, params := rt.routes.lookup(path, params)
+	if method == types.TRACE && !rt.allowTrace {
+		return methodNotAllowed(rt.allowedMethods(n)), nil
+	}
+	if n == nil {
+		return http.NotFoundHandler, nil
+	}
+	if route, ok := n.routes[method]; ok {
+		req.Pattern = route.Pattern
+		return route.handler, params
+	}
+	if n.any != nil {
+		req.Pattern = n.pattern
+		return n.any, params
+	}
 
-		if matched && handler != nil {
-			return handler, nil
+	switch method {
+	case types.HEAD:
+		if route, ok := n.routes[types.GET]; ok {
+			req.Pattern = route.Pattern
+			return route.handler, params
 		}
+	case types.OPTIONS:
+		return optionsHandler(rt.allowedMethods(n)), nil
+	case types.TRACE:
+		return traceHandler, nil
+	}
+	return methodNotAllowed(rt.allowedMethods(n)), nil
+}
+
+// allowedMethods lists the methods that can be used on the route ending at n,
+// sorted. A nil node stands for the server as a whole ("OPTIONS *").
+func (rt *Router) allowedMethods(n *node) []types.Method {
+	var allowed []types.Method
+	if n != nil {
+		allowed = n.methods()
+	} else {
+		rt.routes.walk(func(n *node) {
+			allowed = append(allowed, n.methods()...)
+		})
 	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	if slices.Contains(allowed, types.GET) {
+		allowed = append(allowed, types.HEAD)
+	}
+	allowed = append(allowed, types.OPTIONS)
+	if rt.allowTrace {
+		allowed = append(allowed, types.TRACE)
+	}
+	slices.Sort(allowed)
+	return slices.Compact(allowed)
+}
 
-	return http.NotFoundHandler, nil
+func allowHeader(methods []types.Method) string {
+	names := make([]string, len(methods))
+	for i, m := range methods {
+		names[i] = string(m)
+	}
+	return strings.Join(names, ", ")
+}
+
+// optionsHandler answers OPTIONS with the Allow header and an empty body.
+func optionsHandler(allowed []types.Method) Handler {
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		w.Headers.Set("Allow", allowHeader(allowed))
+		w.Status = types.NoContent
+		return w.SendResponse(nil)
+	}
+}
+
+// methodNotAllowed answers 405 with the methods that are allowed instead.
+func methodNotAllowed(allowed []types.Method) Handler {
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		w.Headers.Set("Allow", allowHeader(allowed))
+		return http.MethodNotAllowedHandler(w, r)
+	}
+}
+
+// traceHandler echoes the request head back as message/http. Credentials
+// are left out so a TRACE cannot be used to read them back.
+func traceHandler(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s\r\n", r.RequestLine.Method, r.RequestLine.Path, r.RequestLine.Version)
+	r.Headers.ForEach(func(key, value string) {
+		switch key {
+		case "Authorization", "Proxy-Authorization", "Cookie":
+			return
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	})
+	b.WriteString("\r\n")
+	w.Headers.Set("Content-Type", "message/http")
+	return w.SendResponse([]byte(b.String()))
 }
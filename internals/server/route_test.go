@@ -0,0 +1,24 @@
+package server
+
+import "testing"
+
+func TestCountParams(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{"/", 0},
+		{"/users", 0},
+		{"/users/{id}", 1},
+		{"/users/{id:int}/posts/{post}", 2},
+		{"/codes/{code:[0-9]{3}}", 1},
+		{"/codes/{code:[a-z]{2,4}}/{rest...}", 2},
+		{"/files/*path", 1},
+		{"/docs/{page?}", 1},
+	}
+	for _, tt := range tests {
+		if got := countParams(tt.pattern); got != tt.want {
+			t.Errorf("countParams(%q) = %d, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"errors"
+
+	http "myserver/internals/http"
+	types "myserver/internals/type"
+)
+
+// Router holds a route tree and the middleware wrapping everything it
+// dispatches, 404s and 405s included. A Router works on its own: build it in
+// a feature package, test it by calling Serve directly, then attach it to a
+// Server (or another Router) with Mount.
+type Router struct {
+	routes      *node
+	maxParams   int
+	middlewares *MiddlewareChain
+	allowTrace  bool
+
+	registered []*Route
+	names      map[string]*Route
+	mounts     []mount
+	// errorStatuses are the MapError mappings, in registration order
+	errorStatuses []errorStatus
+}
+
+type errorStatus struct {
+	target error
+	status types.StatusCode
+}
+
+// mount records a router attached with Mount, for URLFor.
+type mount struct {
+	prefix   string
+	matchers matchers
+	sub      *Router
+	// middlewares given to Mount, for introspection
+	middlewares []Middleware
+}
+
+func NewRouter() *Router {
+	return &Router{
+		routes:      &node{},
+		middlewares: NewMiddlewareChain(),
+		names:       make(map[string]*Route),
+	}
+}
+
+// Use adds middleware that runs for every request the router dispatches.
+func (rt *Router) Use(middleware Middleware) {
+	rt.middlewares.Use(middleware)
+}
+
+// EnableTrace turns on the built-in TRACE echo. TRACE is refused with 405
+// by default since it can leak information to scripts.
+func (rt *Router) EnableTrace(enabled bool) {
+	rt.allowTrace = enabled
+}
+
+// MapError makes the router answer status when a handler fails with an
+// error matching target (with errors.Is), using the error text as the
+// message. It applies to errors returned by JSON handlers, which keep the
+// original error in RouteError.Err. Mappings are checked in registration
+// order, and those of a mounted router win over the outer router's:
+//
+//	server.MapError(store.ErrNotFound, types.NotFound)
+//
+// Like Handle, it must be called before the router serves requests.
+func (rt *Router) MapError(target error, status types.StatusCode) {
+	rt.errorStatuses = append(rt.errorStatuses, errorStatus{target, status})
+}
+
+// Serve routes r to the matching handler. It has the Handler signature, so a
+// router can be registered anywhere a handler can. Captured parameters are
+// appended to r.Params.
+func (rt *Router) Serve(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+	handler, params := rt.resolve(r, r.Params)
+	r.Params = params
+	return rt.middlewares.Apply(rt.mapErrors(handler))(w, r)
+}
+
+// mapErrors applies the MapError mappings to the errors handler returns,
+// before the router's middleware sees them.
+func (rt *Router) mapErrors(handler Handler) Handler {
+	if len(rt.errorStatuses) == 0 {
+		return handler
+	}
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		routeErr := handler(w, r)
+		if routeErr == nil || routeErr.Err == nil {
+			return routeErr
+		}
+		for _, e := range rt.errorStatuses {
+			if errors.Is(routeErr.Err, e.target) {
+				return &types.RouteError{Code: e.status, Message: routeErr.Err.Error()}
+			}
+		}
+		return routeErr
+	}
+}
+
+// Mount serves every path under prefix, for every method, with sub. The
+// prefix is stripped before sub matches, so a router mounted at "/admin"
+// sees "/admin/users" as "/users". Parameters captured in prefix (e.g.
+// "/orgs/{org}") stay visible to sub's handlers. Middlewares given here run
+// before sub's own.
+func (rt *Router) Mount(prefix string, sub *Router, middlewares ...Middleware) {
+	pattern := joinPath(prefix, "{"+mountParam+"...}")
+	mounted := NewMiddlewareChain().With(middlewares...).Apply(func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		// the catch-all holding the rest of the path is always last
+		rest := r.Params[len(r.Params)-1].Value
+		savedPath, savedParams, savedPattern := r.RoutePath, r.Params, r.Pattern
+		r.RoutePath = "/" + rest
+		// cap the slice so sub's parameters are appended to a copy rather
+		// than over the saved catch-all, which outer middleware still sees
+		n := len(r.Params) - 1
+		r.Params = r.Params[:n:n]
+		defer func() {
+			r.RoutePath, r.Params, r.Pattern = savedPath, savedParams, savedPattern
+		}()
+		return sub.Serve(w, r)
+	})
+	ends, matchers := rt.routes.insert(pattern)
+	for _, n := range ends {
+		if n.any != nil {
+			panic(conflict(pattern, n.pattern, "a router is already mounted there"))
+		}
+		n.any = mounted
+	}
+	rt.mounts = append(rt.mounts, mount{prefix: joinPath(prefix, ""), matchers: matchers, sub: sub, middlewares: middlewares})
+	rt.maxParams = max(rt.maxParams, countParams(pattern)+sub.maxParams)
+}
+
+// mountParam names the catch-all a mount point captures the rest of the
+// path into. It is removed before the mounted router sees the request.
+const mountParam = "mount"
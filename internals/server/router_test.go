@@ -0,0 +1,301 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	http "myserver/internals/http"
+	types "myserver/internals/type"
+	url "myserver/internals/utils"
+)
+
+// echo answers with the route path and the parameters the handler sees.
+func echo(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+	return w.SendResponse(fmt.Appendf(nil, "%s %v", r.RoutePath, r.Params))
+}
+
+func TestRouterServe(t *testing.T) {
+	rt := NewRouter()
+	rt.Handle(types.GET, "/users/{id:int}", echo)
+	rt.Handle(types.GET, "/users/me", text("me"))
+	rt.Handle(types.POST, "/users", text("created"))
+
+	tests := []struct {
+		method types.Method
+		target string
+		status int
+		body   string
+		allow  string
+	}{
+		{types.GET, "/users/7", 200, " [{id 7}]", ""},
+		{types.GET, "/users/me", 200, "me", ""},
+		{types.GET, "/users/abc", 404, "", ""},
+		{types.POST, "/users", 200, "created", ""},
+		{types.DELETE, "/users", 405, "", "OPTIONS, POST"},
+		{types.HEAD, "/users/me", 200, "", ""},
+	}
+	for _, tt := range tests {
+		resp := serve(t, rt.Serve, tt.method, tt.target)
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, resp.StatusCode, tt.status)
+			continue
+		}
+		if tt.status == 200 && resp.body != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.target, resp.body, tt.body)
+		}
+		if allow := resp.Header.Get("Allow"); allow != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.target, allow, tt.allow)
+		}
+	}
+}
+
+func TestRouterDecodedPath(t *testing.T) {
+	rt := NewRouter()
+	rt.Handle(types.GET, "/a b", text("space"))
+	rt.Handle(types.GET, "/files/{name}", echo)
+	rt.Handle(types.GET, "/search", func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		return w.SendResponse([]byte(r.URL.Query().Get("q")))
+	})
+
+	tests := []struct {
+		target string
+		status int
+		body   string
+	}{
+		{"/a%20b", 200, "space"},
+		{"/a%20b?x=1", 200, "space"},
+		{"/files/caf%C3%A9?v=2", 200, " [{name café}]"},
+		{"/files/report.pdf?download", 200, " [{name report.pdf}]"},
+		{"/search?q=a+b%26c", 200, "a b&c"},
+		{"/search?q=/files/x", 200, "/files/x"},
+		// the query is never part of the routed path
+		{"/files?name=x", 404, ""},
+		{"/a%20b%3Fx=1", 404, ""},
+	}
+	for _, tt := range tests {
+		resp := serve(t, rt.Serve, types.GET, tt.target)
+		if resp.StatusCode != tt.status || (tt.status == 200 && resp.body != tt.body) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.target, resp.StatusCode, resp.body, tt.status, tt.body)
+		}
+	}
+}
+
+func TestRouterGroup(t *testing.T) {
+	rt := NewRouter()
+	var seen []string
+	api := rt.Group("/api", record(&seen, "api"))
+	api.Handle(types.GET, "/ping", text("pong"))
+	v1 := api.Group("v1/")
+	v1.Handle(types.GET, "/items/{id}", echo)
+	rt.Handle(types.GET, "/health", text("ok"))
+
+	tests := []struct {
+		target string
+		status int
+		body   string
+		// whether the group middleware ran
+		group bool
+	}{
+		{"/api/ping", 200, "pong", true},
+		{"/api/v1/items/3", 200, " [{id 3}]", true},
+		{"/health", 200, "ok", false},
+		{"/api/unknown", 404, "", false},
+	}
+	for _, tt := range tests {
+		seen = nil
+		resp := serve(t, rt.Serve, types.GET, tt.target)
+		if resp.StatusCode != tt.status || (tt.status == 200 && resp.body != tt.body) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.target, resp.StatusCode, resp.body, tt.status, tt.body)
+		}
+		if ran := len(seen) > 0; ran != tt.group {
+			t.Errorf("GET %s: group middleware ran = %v, want %v", tt.target, ran, tt.group)
+		}
+	}
+}
+
+func TestRouterMount(t *testing.T) {
+	users := NewRouter()
+	users.Handle(types.GET, "/", text("list"))
+	users.Handle(types.GET, "/{id}", echo)
+
+	rt := NewRouter()
+	rt.Mount("/orgs/{org}/users", users)
+	rt.Handle(types.GET, "/orgs/{org}", echo)
+
+	tests := []struct {
+		target string
+		status int
+		body   string
+	}{
+		{"/orgs/acme/users", 200, "list"},
+		{"/orgs/acme/users/", 200, "list"},
+		{"/orgs/acme/users/7", 200, "/7 [{org acme} {id 7}]"},
+		{"/orgs/acme", 200, " [{org acme}]"},
+		{"/orgs/acme/users/7/extra", 404, ""},
+	}
+	for _, tt := range tests {
+		resp := serve(t, rt.Serve, types.GET, tt.target)
+		if resp.StatusCode != tt.status || (tt.status == 200 && resp.body != tt.body) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.target, resp.StatusCode, resp.body, tt.status, tt.body)
+		}
+	}
+}
+
+func TestMountRestoresParams(t *testing.T) {
+	sub := NewRouter()
+	sub.Handle(types.GET, "/{id}", echo)
+
+	var before, after string
+	inspect := func(next Handler) Handler {
+		return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+			before = fmt.Sprint(r.RoutePath, r.Params)
+			err := next(w, r)
+			after = fmt.Sprint(r.RoutePath, r.Params)
+			return err
+		}
+	}
+	rt := NewRouter()
+	rt.Mount("/u", sub, inspect)
+
+	// a buffer with spare capacity, like the one handleConnection reuses
+	withBuffer := func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		r.Params = make(url.Params, 0, 8)
+		return rt.Serve(w, r)
+	}
+
+	resp := serve(t, withBuffer, types.GET, "/u/7")
+	if resp.body != "/7 [{id 7}]" {
+		t.Fatalf("GET /u/7 body = %q", resp.body)
+	}
+	if want := "[{mount 7}]"; before != want || after != want {
+		t.Errorf("mount middleware saw %q before and %q after next, want %q both times", before, after, want)
+	}
+}
+
+func TestRouterConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(rt *Router)
+		panics   string
+	}{
+		{
+			name: "same method and pattern",
+			register: func(rt *Router) {
+				rt.Handle(types.GET, "/a/{id}", echo)
+				rt.Handle(types.GET, "/a/{id}", echo)
+			},
+			panics: "both handle GET",
+		},
+		{
+			name: "parameters with different names",
+			register: func(rt *Router) {
+				rt.Handle(types.GET, "/users/{id}", echo)
+				rt.Handle(types.DELETE, "/users/{name}", echo)
+			},
+			panics: "match the same segments",
+		},
+		{
+			name: "optional segment shadowing a route",
+			register: func(rt *Router) {
+				rt.Handle(types.GET, "/docs", echo)
+				rt.Handle(types.GET, "/docs/{page?}", echo)
+			},
+			panics: "both handle GET",
+		},
+		{
+			name: "two mounts on one prefix",
+			register: func(rt *Router) {
+				rt.Mount("/admin", NewRouter())
+				rt.Mount("/admin", NewRouter())
+			},
+			panics: "already mounted",
+		},
+		{
+			name: "invalid pattern",
+			register: func(rt *Router) {
+				rt.Handle(types.GET, "/a/{id", echo)
+			},
+			panics: "invalid route pattern",
+		},
+		{
+			name: "required segment after an optional one",
+			register: func(rt *Router) {
+				rt.Handle(types.GET, "/a/{b?}/c", echo)
+			},
+			panics: "follows optional parameter",
+		},
+		{
+			name: "same name twice",
+			register: func(rt *Router) {
+				rt.Handle(types.GET, "/a", echo).Name("a")
+				rt.Handle(types.GET, "/b", echo).Name("a")
+			},
+			panics: "already used",
+		},
+		{
+			name: "different methods",
+			register: func(rt *Router) {
+				rt.Handle(types.GET, "/a/{id}", echo)
+				rt.Handle(types.PUT, "/a/{id}", echo)
+				rt.Handle(types.GET, "/a/{id:int}", echo)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				switch {
+				case tt.panics == "" && r != nil:
+					t.Errorf("unexpected panic: %v", r)
+				case tt.panics != "" && r == nil:
+					t.Errorf("no panic, want one containing %q", tt.panics)
+				case tt.panics != "" && !strings.Contains(fmt.Sprint(r), tt.panics):
+					t.Errorf("panic %q, want one containing %q", r, tt.panics)
+				}
+			}()
+			tt.register(NewRouter())
+		})
+	}
+}
+
+func TestRequestPattern(t *testing.T) {
+	var outer, inner string
+	pattern := func(dst *string) Handler {
+		return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+			*dst = r.Pattern
+			return w.SendResponse(nil)
+		}
+	}
+	sub := NewRouter()
+	sub.Handle(types.GET, "/{id}", pattern(&inner))
+	keep := func(next Handler) Handler {
+		return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+			err := next(w, r)
+			outer = r.Pattern
+			return err
+		}
+	}
+	rt := NewRouter()
+	rt.Mount("/u", sub, keep)
+	rt.Group("/api").Handle(types.GET, "/items/{id:int}", pattern(&inner))
+
+	tests := []struct {
+		method       types.Method
+		target       string
+		inner, outer string
+	}{
+		{types.GET, "/api/items/7", "/api/items/{id:int}", ""},
+		{types.HEAD, "/api/items/7", "/api/items/{id:int}", ""},
+		// the mounted router sets its own, the mount restores the outer one
+		{types.GET, "/u/7", "/{id}", "/u/{mount...}"},
+	}
+	for _, tt := range tests {
+		inner, outer = "", ""
+		serve(t, rt.Serve, tt.method, tt.target)
+		if inner != tt.inner || outer != tt.outer {
+			t.Errorf("%s %s: Pattern = %q inside, %q after the mount; want %q, %q", tt.method, tt.target, inner, outer, tt.inner, tt.outer)
+		}
+	}
+}
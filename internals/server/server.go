@@ -1,74 +1,124 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"time"
 
 	http "myserver/internals/http"
 	types "myserver/internals/type"
+	url "myserver/internals/utils"
 )
 
+// Server accepts connections and dispatches requests to its Router, whose
+// methods (Handle, Group, Mount, ...) are available on the server itself.
+// Per-host routers added with Host take precedence over it.
 type Server struct {
+	*Router
 	closed      bool
 	listener    net.Listener
 	idleTimeout time.Duration
 	middlewares *MiddlewareChain
-	routes      Routes
+	hosts       map[string]*Router
+	limits      http.Limits
 }
 
 func NewServer(keepAlive time.Duration) *Server {
 	return &Server{
+		Router:      NewRouter(),
 		closed:      false,
 		idleTimeout: keepAlive,
 		middlewares: NewMiddlewareChain(),
-		routes:      make(Routes),
+		hosts:       make(map[string]*Router),
+		limits:      http.DefaultLimits,
 	}
 }
 
+// Use adds middleware that runs for every request on every host, before
+// routing, so it also sees 404s.
 func (s *Server) Use(middleware Middleware) {
 	s.middlewares.Use(middleware)
 }
 
+// dispatch hands the request to the router of its host.
+func (s *Server) dispatch(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+	return s.routerFor(r.Host).Serve(w, r)
+}
+
+// SetLimits changes the request size limits enforced on new requests.
+// Zero fields keep their default value.
+func (s *Server) SetLimits(limits http.Limits) {
+	s.limits = limits
+}
+
+// handleConnection serves requests one after another on conn. The reader
+// keeps bytes that arrived ahead of time, so pipelined requests are answered
+// in the order they were sent.
 func handleConnection(conn net.Conn, s *Server) {
 	defer conn.Close()
 
+	reader := http.NewReader(conn, s.limits)
+	// route parameters are captured into the same buffer for every request
+	params := make(url.Params, 0, s.maxParams)
 	for {
 		if s.idleTimeout > 0 {
 			_ = conn.SetDeadline(time.Now().Add(s.idleTimeout))
 		}
 
-		req, err := http.ParseRequest(conn)
+		req, err := reader.ReadRequest()
 		response := http.NewResponseWriter(conn, s.idleTimeout)
 		if err != nil {
-			response.SendBadRequest(err.Error())
+			// the client went away or stayed idle; nobody to answer
+			var netErr net.Error
+			if errors.Is(err, io.EOF) || (errors.As(err, &netErr) && netErr.Timeout()) {
+				return
+			}
+			response.SendError(http.ErrorStatus(err), err.Error())
 			return
 		}
 
-		handler, params := s.FindRoute(req.RequestLine.Path, req.RequestLine.Method)
-
-		finalHandler := s.middlewares.Apply(handler)
-
-		req.Params = params
+		req.Params = params[:0]
 		keepAlive := req.IsKeepAlive()
 		response.SetKeppAlive(keepAlive)
+		response.SetRequest(req)
 
-		if routeErr := finalHandler(response, req); routeErr != nil {
-			switch routeErr.Code {
-			case types.NotFound:
-				response.SendNotFound(routeErr.Message)
-			case types.MethodNotAllowed:
-				response.SendBadRequest(routeErr.Message)
-			default:
-				response.SendInternalServerError(routeErr.Message)
-			}
+		if routeErr := s.middlewares.Apply(s.dispatch)(response, req); routeErr != nil {
+			sendRouteError(response, routeErr)
+		}
+		_ = req.Finish()
+		if cap(req.Params) > cap(params) {
+			params = req.Params
 		}
 		if !keepAlive {
 			return
 		}
+		// drain whatever the handler did not read so the next request
+		// starts at a message boundary
+		if err := req.Body.Close(); err != nil {
+			return
+		}
 	}
 }
 
+// sendRouteError answers with the status carried by the error. Codes the
+// server cannot put on a status line become 500.
+func sendRouteError(w *http.ResponseWriter, routeErr *types.RouteError) {
+	code := routeErr.Code
+	if _, ok := types.StatusText[code]; !ok || code < 400 {
+		code = types.InternalServerError
+	}
+	if len(routeErr.Fields) > 0 {
+		w.SendJSON(struct {
+			Error  string             `json:"error"`
+			Fields []types.FieldError `json:"fields"`
+		}{routeErr.Message, routeErr.Fields}, code)
+		return
+	}
+	w.SendError(code, routeErr.Message)
+}
+
 func (s *Server) acceptor() {
 	for {
 		if s.closed {
@@ -86,14 +136,22 @@ func (s *Server) acceptor() {
 	}
 }
 
-func ServeHTTP(port uint16) (*Server, error) {
-	server := NewServer(10 * time.Second)
+// Listen starts accepting connections on port in the background.
+func (s *Server) Listen(port uint16) error {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
+		return err
+	}
+	s.listener = listener
+	go s.acceptor()
+	return nil
+}
+
+func ServeHTTP(port uint16) (*Server, error) {
+	server := NewServer(10 * time.Second)
+	if err := server.Listen(port); err != nil {
 		return nil, err
 	}
-	server.listener = listener
-	go server.acceptor()
 	return server, nil
 }
 
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	nethttp "net/http"
+	"strings"
+	"testing"
+
+	http "myserver/internals/http"
+	types "myserver/internals/type"
+)
+
+// response is what a test got back from serve.
+type response struct {
+	*nethttp.Response
+	body string
+}
+
+// serve runs one request through handler the way handleConnection does and
+// parses what was written. headers are "Name: value" lines.
+func serve(t *testing.T, handler Handler, method types.Method, target string, headers ...string) response {
+	t.Helper()
+	req := parseRequest(t, string(method)+" "+target, headers...)
+
+	var buf bytes.Buffer
+	w := http.NewResponseWriter(&buf, 0)
+	w.SetRequest(req)
+	if routeErr := handler(w, req); routeErr != nil {
+		sendRouteError(w, routeErr)
+	}
+	_ = req.Finish()
+
+	resp, err := nethttp.ReadResponse(bufio.NewReader(&buf), &nethttp.Request{Method: string(method)})
+	if err != nil {
+		t.Fatalf("%s %s: unreadable response %q: %v", method, target, buf.String(), err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: reading body: %v", method, target, err)
+	}
+	return response{resp, string(body)}
+}
+
+// parseRequest parses a request without a body. line is the start of the
+// request line, "METHOD target", or just the target for a GET.
+func parseRequest(t *testing.T, line string, headers ...string) *http.Request {
+	t.Helper()
+	if strings.HasPrefix(line, "/") {
+		line = "GET " + line
+	}
+	raw := line + " HTTP/1.1\r\nHost: example.com\r\n"
+	for _, h := range headers {
+		raw += h + "\r\n"
+	}
+	req, err := http.ParseRequest(strings.NewReader(raw + "\r\n"))
+	if err != nil {
+		t.Fatalf("ParseRequest %q: %v", line, err)
+	}
+	return req
+}
+
+// text answers with a fixed body, for routes whose handler does not matter.
+func text(body string) Handler {
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		return w.SendResponse([]byte(body))
+	}
+}
@@ -0,0 +1,307 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	types "myserver/internals/type"
+	url "myserver/internals/utils"
+)
+
+// node is one vertex of the compressed radix tree holding every route.
+//
+// Static text is stored compressed: a node owns a prefix of one or more bytes
+// and its static children start with distinct bytes. A "{name}" parameter is
+// its own node matching exactly one path segment, optionally checked against
+// a constraint such as "{id:int}". Matching prefers static
+// children over parameters and backtracks when a branch dead-ends, so
+// precedence does not depend on registration order.
+type node struct {
+	prefix  string  // static text matched by this node
+	indices string  // first byte of every static child, same order as static
+	static  []*node // static children
+	params  []*node // parameter children, constrained ones first
+	// catch-all child, tried last; it captures the rest of the path
+	catchAll *node
+
+	// parameter nodes: the name and the optional "{name:constraint}" check
+	param      string
+	constraint string
+	matches    func(string) bool
+
+	// owner is the pattern that created a parameter or catch-all node,
+	// reported when a later registration conflicts with it
+	owner string
+
+	// set on nodes where a route ends
+	pattern string
+	routes  map[types.Method]*Route
+	// any answers every method without its own route; used by Mount
+	any Handler
+}
+
+// isRoute reports whether a route ends on n.
+func (n *node) isRoute() bool {
+	return len(n.routes) > 0 || n.any != nil
+}
+
+// handler returns the handler for method, falling back to any.
+func (n *node) handler(method types.Method) Handler {
+	if route, ok := n.routes[method]; ok {
+		return route.handler
+	}
+	return n.any
+}
+
+// insert adds pattern to the tree and returns the nodes the route ends on:
+// one per variant, since every optional "{name?}" segment adds one. It also
+// returns the constraint matchers of the pattern's parameters by name, so
+// URLFor checks values without compiling them again.
+// Invalid patterns and parameters that make an earlier route ambiguous
+// panic, like any other programming error caught at registration.
+func (n *node) insert(pattern string) ([]*node, matchers) {
+	if err := url.ValidatePattern(pattern); err != nil {
+		panic("server: " + err.Error())
+	}
+	var ends []*node
+	m := make(matchers)
+	for _, variant := range expandOptional(strings.Split(strings.Trim(pattern, "/"), "/")) {
+		end := n.insertSegments(pattern, variant, m)
+		if end.pattern == "" {
+			end.pattern = pattern
+		}
+		ends = append(ends, end)
+	}
+	return ends, m
+}
+
+// matchers holds the constraint checks of a pattern's parameters by name.
+// Parameters without a constraint are left out.
+type matchers map[string]func(string) bool
+
+// expandOptional returns the variants of a pattern with optional "{name?}"
+// segments: the route may stop right before any optional segment, otherwise
+// it continues with the segment present. "/d/{a?}/{b?}" gives "/d",
+// "/d/{a}" and "/d/{a}/{b}".
+func expandOptional(segments []string) [][]string {
+	for i, seg := range segments {
+		if !url.IsParam(seg) || url.IsCatchAll(seg) {
+			continue
+		}
+		if _, _, optional := url.ParseParam(seg); !optional {
+			continue
+		}
+		variants := [][]string{segments[:i:i]}
+		for _, rest := range expandOptional(segments[i+1:]) {
+			variants = append(variants, slices.Concat(segments[:i+1], rest))
+		}
+		return variants
+	}
+	return [][]string{segments}
+}
+
+// insertSegments adds one variant of pattern. Consecutive static segments are
+// stored as a single prefix; every parameter segment becomes its own node,
+// whose matcher is recorded in m.
+func (n *node) insertSegments(pattern string, segments []string, m matchers) *node {
+	current := n
+	static := "/"
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch {
+		case url.IsCatchAll(seg):
+			name, _, _ := url.ParseParam(seg)
+			// the catch-all also matches the bare prefix, so leave the
+			// separating slash to it
+			current = current.insertStatic(strings.TrimSuffix(static, "/"))
+			return current.insertCatchAll(pattern, name)
+		case url.IsParam(seg):
+			name, constraint, _ := url.ParseParam(seg)
+			current = current.insertStatic(static)
+			current = current.insertParam(pattern, name, constraint)
+			if current.matches != nil {
+				m[name] = current.matches
+			}
+			static = ""
+			if !last {
+				static = "/"
+			}
+		default:
+			static += seg
+			if !last {
+				static += "/"
+			}
+		}
+	}
+	return current.insertStatic(static)
+}
+
+// insertStatic walks s through the static children, splitting a child when s
+// only shares part of its prefix.
+func (n *node) insertStatic(s string) *node {
+	if s == "" {
+		return n
+	}
+	if i := strings.IndexByte(n.indices, s[0]); i != -1 {
+		child := n.static[i]
+		common := commonPrefix(child.prefix, s)
+		if common < len(child.prefix) {
+			split := *child
+			split.prefix = child.prefix[common:]
+			*child = node{
+				prefix:  child.prefix[:common],
+				indices: split.prefix[:1],
+				static:  []*node{&split},
+			}
+		}
+		return child.insertStatic(s[common:])
+	}
+	child := &node{prefix: s}
+	n.indices += s[:1]
+	n.static = append(n.static, child)
+	return child
+}
+
+// insertParam finds or adds a parameter child. Constrained parameters are
+// kept ahead of unconstrained ones so "{id:int}" is tried before "{name}".
+// Two parameters with the same constraint but different names at the same
+// place ("/users/{id}" and "/users/{name}") would make the second one
+// unreachable, so that panics.
+func (n *node) insertParam(pattern, name, constraint string) *node {
+	for _, p := range n.params {
+		if p.constraint != constraint {
+			continue
+		}
+		if p.param != name {
+			panic(conflict(pattern, p.owner, fmt.Sprintf("{%s} and {%s} match the same segments", name, p.param)))
+		}
+		return p
+	}
+	matches, _ := url.Constraint(constraint)
+	child := &node{param: name, constraint: constraint, matches: matches, owner: pattern}
+	at := len(n.params)
+	if matches != nil {
+		at = slices.IndexFunc(n.params, func(p *node) bool { return p.matches == nil })
+		if at == -1 {
+			at = len(n.params)
+		}
+	}
+	n.params = slices.Insert(n.params, at, child)
+	return child
+}
+
+func (n *node) insertCatchAll(pattern, name string) *node {
+	if n.catchAll == nil {
+		n.catchAll = &node{param: name, owner: pattern}
+	}
+	if n.catchAll.param != name {
+		panic(conflict(pattern, n.catchAll.owner, fmt.Sprintf("catch-alls {%s...} and {%s...} overlap", name, n.catchAll.param)))
+	}
+	return n.catchAll
+}
+
+// conflict formats the panic message for two registrations that clash.
+func conflict(pattern, existing, reason string) string {
+	return fmt.Sprintf("server: route %q conflicts with %q: %s", pattern, existing, reason)
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// lookup finds the node matching path, an already decoded and cleaned path
+// such as Request.URL.Path. Captured parameters are appended to
+// params, so a caller reusing the same slice matches without allocating.
+// A nil node means no route exists for path under any method.
+func (n *node) lookup(path string, params url.Params) (*node, url.Params) {
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" {
+		path = "/"
+	}
+	return n.match(path, params)
+}
+
+// match consumes path below n, which has already matched its own prefix.
+func (n *node) match(path string, params url.Params) (*node, url.Params) {
+	if path == "" {
+		if n.isRoute() {
+			return n, params
+		}
+		return n.matchCatchAll(path, params)
+	}
+
+	// static first: at most one child can start with the next byte
+	if i := strings.IndexByte(n.indices, path[0]); i != -1 {
+		child := n.static[i]
+		if strings.HasPrefix(path, child.prefix) {
+			if found, p := child.match(path[len(child.prefix):], params); found != nil {
+				return found, p
+			}
+		}
+	}
+
+	// then parameters, each one taking a whole non-empty segment
+	mark := len(params)
+	end := strings.IndexByte(path, '/')
+	if end == -1 {
+		end = len(path)
+	}
+	if end > 0 {
+		for _, child := range n.params {
+			if child.matches != nil && !child.matches(path[:end]) {
+				continue
+			}
+			params = append(params[:mark], url.Param{Key: child.param, Value: path[:end]})
+			if found, p := child.match(path[end:], params); found != nil {
+				return found, p
+			}
+		}
+	}
+
+	// last, a catch-all takes "/rest/of/path" (or nothing at all)
+	return n.matchCatchAll(path, params[:mark])
+}
+
+func (n *node) matchCatchAll(path string, params url.Params) (*node, url.Params) {
+	child := n.catchAll
+	if child == nil || !child.isRoute() {
+		return nil, params
+	}
+	if path != "" && path[0] != '/' {
+		return nil, params
+	}
+	return child, append(params, url.Param{Key: child.param, Value: strings.TrimPrefix(path, "/")})
+}
+
+// walk calls fn on every node where a route ends, static children first.
+func (n *node) walk(fn func(n *node)) {
+	if n.isRoute() {
+		fn(n)
+	}
+	for _, child := range n.static {
+		child.walk(fn)
+	}
+	for _, child := range n.params {
+		child.walk(fn)
+	}
+	if n.catchAll != nil {
+		n.catchAll.walk(fn)
+	}
+}
+
+// methods lists the methods registered on n, sorted.
+func (n *node) methods() []types.Method {
+	methods := make([]types.Method, 0, len(n.routes))
+	for m := range n.routes {
+		methods = append(methods, m)
+	}
+	slices.Sort(methods)
+	return methods
+}
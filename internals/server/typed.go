@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"slices"
+	"strings"
+
+	http "myserver/internals/http"
+	types "myserver/internals/type"
+)
+
+// JSON adapts a plain function into a Handler. The input is bound and
+// validated with http.Bind, fn runs with a context carrying the request, and
+// its result is encoded as JSON, or as XML for clients whose Accept header
+// takes XML but not JSON. Returned errors become status codes through
+// the router's MapError mappings, so fn never touches the ResponseWriter:
+//
+//	func getUser(ctx context.Context, in UserParams) (User, error) { ... }
+//
+//	server.Handle(types.GET, "/users/{id:int}", server.JSON(getUser))
+//
+// Use struct{} as In for handlers without input. The response status is 200
+// unless Out implements StatusCoder.
+func JSON[In, Out any](fn func(ctx context.Context, in In) (Out, error)) Handler {
+	return func(w *http.ResponseWriter, r *http.Request) *types.RouteError {
+		in, routeErr := http.Bind[In](r)
+		if routeErr != nil {
+			return routeErr
+		}
+		out, err := fn(context.WithValue(context.Background(), requestKey{}, r), in)
+		if err != nil {
+			return errorFor(err)
+		}
+		return encode(w, r, out)
+	}
+}
+
+type requestKey struct{}
+
+// RequestFromContext returns the request a JSON handler is serving, for the
+// rare function that needs more than its bound input.
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(requestKey{}).(*http.Request)
+	return r, ok
+}
+
+// StatusCoder is implemented by results and errors that pick their own
+// status code.
+type StatusCoder interface {
+	StatusCode() types.StatusCode
+}
+
+type encoder struct {
+	mediaType string
+	marshal   func(any) ([]byte, error)
+}
+
+// encoders are the formats a JSON handler can answer in, the default first.
+var encoders = []encoder{
+	{"application/json", json.Marshal},
+	{"application/xml", xml.Marshal},
+}
+
+// encode answers with the first encoder the client accepts at all, rather
+// than the one it rates highest: browsers rate application/xml above */*,
+// and should still get JSON.
+func encode(w *http.ResponseWriter, r *http.Request, out any) *types.RouteError {
+	accept := strings.Join(r.Headers.Values("Accept"), ",")
+	i := slices.IndexFunc(encoders, func(e encoder) bool {
+		return http.Negotiate(accept, e.mediaType) != ""
+	})
+	if i == -1 {
+		return &types.RouteError{Code: types.NotAcceptable, Message: "no acceptable representation"}
+	}
+
+	status := types.OK
+	if sc, ok := out.(StatusCoder); ok {
+		status = sc.StatusCode()
+	}
+	w.Headers.Add("Vary", "Accept")
+	w.Status = status
+	if status == types.NoContent {
+		return w.SendResponse(nil)
+	}
+	body, err := encoders[i].marshal(out)
+	if err != nil {
+		return &types.RouteError{Code: types.InternalServerError, Message: "failed to encode response"}
+	}
+	w.Headers.Set("Content-Type", encoders[i].mediaType)
+	return w.SendResponse(body)
+}
+
+// errorFor turns an error returned by a JSON handler into a RouteError. A
+// *types.RouteError is kept as is and StatusCoder picks the status; anything
+// else is a 500 whose message does not leak the error text. The error is
+// kept in RouteError.Err, so the router's MapError mappings can still
+// override the status.
+func errorFor(err error) *types.RouteError {
+	var routeErr *types.RouteError
+	if errors.As(err, &routeErr) {
+		return routeErr
+	}
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		return &types.RouteError{Code: sc.StatusCode(), Message: err.Error(), Err: err}
+	}
+	return &types.RouteError{Code: types.InternalServerError, Message: types.StatusText[types.InternalServerError], Err: err}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	types "myserver/internals/type"
+)
+
+var (
+	errMissing = errors.New("item missing")
+	errLocked  = errors.New("item locked")
+)
+
+type invalidError struct{}
+
+func (invalidError) Error() string                { return "already taken" }
+func (invalidError) StatusCode() types.StatusCode { return types.UnprocessableContent }
+
+type itemParams struct {
+	ID string `param:"id"`
+}
+
+type item struct {
+	ID string `json:"id" xml:"id"`
+}
+
+func getItem(ctx context.Context, in itemParams) (item, error) {
+	switch in.ID {
+	case "missing":
+		return item{}, errMissing
+	case "locked":
+		return item{}, errLocked
+	case "dup":
+		return item{}, invalidError{}
+	case "boom":
+		return item{}, errors.New("database password is hunter2")
+	}
+	return item{ID: in.ID}, nil
+}
+
+// browserAccept is what browsers send for a page load.
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+// getInfo answers with a map, which encoding/xml cannot marshal.
+func getInfo(ctx context.Context, _ struct{}) (map[string]string, error) {
+	return map[string]string{"status": "ok"}, nil
+}
+
+func TestJSONHandler(t *testing.T) {
+	rt := NewRouter()
+	rt.Handle(types.GET, "/items/{id}", JSON(getItem))
+	rt.Handle(types.GET, "/info", JSON(getInfo))
+	rt.MapError(errMissing, types.NotFound)
+
+	tests := []struct {
+		target string
+		accept string
+		status int
+		body   string
+		ctype  string
+	}{
+		{"/items/7", "", 200, `{"id":"7"}`, "application/json"},
+		{"/items/7", "application/xml", 200, `<item><id>7</id></item>`, "application/xml"},
+		{"/items/7", "text/xml;q=0.5, application/*;q=0", 406, "", ""},
+		{"/items/7", "text/csv", 406, "", ""},
+		// JSON wins whenever it is acceptable at all
+		{"/items/7", browserAccept, 200, `{"id":"7"}`, "application/json"},
+		{"/items/7", "application/xml, application/json;q=0.1", 200, `{"id":"7"}`, "application/json"},
+		{"/info", browserAccept, 200, `{"status":"ok"}`, "application/json"},
+		{"/items/missing", "", 404, "item missing", ""},
+		{"/items/dup", "", 422, "already taken", ""},
+		{"/items/locked", "", 500, "Internal Server Error", ""},
+		{"/items/boom", "", 500, "Internal Server Error", ""},
+	}
+	for _, tt := range tests {
+		var headers []string
+		if tt.accept != "" {
+			headers = append(headers, "Accept: "+tt.accept)
+		}
+		resp := serve(t, rt.Serve, types.GET, tt.target, headers...)
+		if resp.StatusCode != tt.status {
+			t.Errorf("GET %s (%s) = %d %q, want %d", tt.target, tt.accept, resp.StatusCode, resp.body, tt.status)
+			continue
+		}
+		if tt.body != "" && resp.body != tt.body {
+			t.Errorf("GET %s (%s) body = %q, want %q", tt.target, tt.accept, resp.body, tt.body)
+		}
+		if tt.ctype != "" && resp.Header.Get("Content-Type") != tt.ctype {
+			t.Errorf("GET %s (%s) Content-Type = %q, want %q", tt.target, tt.accept, resp.Header.Get("Content-Type"), tt.ctype)
+		}
+		if strings.Contains(resp.body, "hunter2") {
+			t.Errorf("GET %s leaked the error text: %q", tt.target, resp.body)
+		}
+	}
+}
+
+func TestMapErrorScope(t *testing.T) {
+	inner := NewRouter()
+	inner.Handle(types.GET, "/items/{id}", JSON(getItem))
+	inner.MapError(errLocked, types.Unauthorized)
+
+	outer := NewRouter()
+	outer.Handle(types.GET, "/items/{id}", JSON(getItem))
+	outer.MapError(errLocked, types.Forbidden)
+	outer.MapError(errMissing, types.NotFound)
+	outer.Mount("/inner", inner)
+
+	other := NewRouter()
+	other.Handle(types.GET, "/items/{id}", JSON(getItem))
+
+	tests := []struct {
+		router *Router
+		target string
+		status int
+	}{
+		{outer, "/items/locked", 403},
+		{outer, "/items/missing", 404},
+		// the mounted router's own mapping wins, the outer one fills in
+		{outer, "/inner/items/locked", 401},
+		{outer, "/inner/items/missing", 404},
+		// mappings do not leak into unrelated routers
+		{other, "/items/locked", 500},
+		{other, "/items/missing", 500},
+	}
+	for _, tt := range tests {
+		if resp := serve(t, tt.router.Serve, types.GET, tt.target); resp.StatusCode != tt.status {
+			t.Errorf("GET %s = %d %q, want %d", tt.target, resp.StatusCode, resp.body, tt.status)
+		}
+	}
+}
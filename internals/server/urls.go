@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"slices"
+	"strings"
+
+	url "myserver/internals/utils"
+)
+
+var (
+	ErrUnknownRoute = errors.New("unknown route name")
+	ErrMissingParam = errors.New("missing route parameter")
+	ErrInvalidParam = errors.New("route parameter does not match its constraint")
+)
+
+// URLFor builds the path of the route registered under name, e.g.
+//
+//	server.URLFor("search", map[string]string{"firstID": "1", "secondID": "go-1", "page": "2"})
+//	// "/search/1/ds/go-1?page=2"
+//
+// Values are percent-escaped; a catch-all value keeps its slashes. Values not
+// used by the pattern are appended as the query string, sorted by key. An
+// optional parameter that is left out ends the path there. Routes of mounted
+// routers are found too, with the mount prefix in front.
+func (rt *Router) URLFor(name string, params map[string]string) (string, error) {
+	used := make(map[string]bool)
+	path, err := rt.buildPath(name, params, used)
+	if err != nil {
+		return "", err
+	}
+
+	var query []string
+	for key, value := range params {
+		if !used[key] {
+			query = append(query, neturl.QueryEscape(key)+"="+neturl.QueryEscape(value))
+		}
+	}
+	if len(query) == 0 {
+		return path, nil
+	}
+	slices.Sort(query)
+	return path + "?" + strings.Join(query, "&"), nil
+}
+
+func (rt *Router) buildPath(name string, params map[string]string, used map[string]bool) (string, error) {
+	if route, ok := rt.names[name]; ok {
+		return fillPattern(route.Pattern, route.matchers, params, used)
+	}
+	for _, m := range rt.mounts {
+		rest, err := m.sub.buildPath(name, params, used)
+		if errors.Is(err, ErrUnknownRoute) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		prefix, err := fillPattern(m.prefix, m.matchers, params, used)
+		if err != nil {
+			return "", err
+		}
+		return joinPath(prefix, rest), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownRoute, name)
+}
+
+// fillPattern substitutes params into pattern and marks the ones it used.
+// Values are checked with the matchers the tree compiled for pattern.
+func fillPattern(pattern string, matchers matchers, params map[string]string, used map[string]bool) (string, error) {
+	var b strings.Builder
+	for seg := range strings.SplitSeq(strings.Trim(pattern, "/"), "/") {
+		if !url.IsParam(seg) && !url.IsCatchAll(seg) {
+			if seg != "" {
+				b.WriteString("/" + seg)
+			}
+			continue
+		}
+		name, _, optional := url.ParseParam(seg)
+		value, ok := params[name]
+		if url.IsCatchAll(seg) {
+			used[name] = true
+			for part := range strings.SplitSeq(strings.Trim(value, "/"), "/") {
+				if part != "" {
+					b.WriteString("/" + neturl.PathEscape(part))
+				}
+			}
+			break
+		}
+		if !ok || value == "" {
+			if optional {
+				break
+			}
+			return "", fmt.Errorf("%w: %q in %q", ErrMissingParam, name, pattern)
+		}
+		if matches := matchers[name]; matches != nil && !matches(value) {
+			return "", fmt.Errorf("%w: %s=%q in %q", ErrInvalidParam, name, value, pattern)
+		}
+		used[name] = true
+		b.WriteString("/" + neturl.PathEscape(value))
+	}
+	if b.Len() == 0 {
+		return "/", nil
+	}
+	return b.String(), nil
+}
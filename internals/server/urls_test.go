@@ -0,0 +1,45 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	types "myserver/internals/type"
+)
+
+func TestURLFor(t *testing.T) {
+	admin := NewRouter()
+	admin.Handle(types.GET, "/users/{id:int}", echo).Name("admin.user")
+
+	rt := NewRouter()
+	rt.Handle(types.GET, "/search/{firstID:int}/ds/{secondID:[a-z0-9-]+}", echo).Name("search")
+	rt.Handle(types.GET, "/docs/{page?}", echo).Name("docs")
+	rt.Handle(types.GET, "/files/{path...}", echo).Name("files")
+	rt.Handle(types.GET, "/tags/{tag}", echo).Name("tag")
+	rt.Mount("/orgs/{org:[a-z]+}/admin", admin)
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   string
+		err    error
+	}{
+		{"search", map[string]string{"firstID": "1", "secondID": "go-1", "page": "2"}, "/search/1/ds/go-1?page=2", nil},
+		{"search", map[string]string{"firstID": "1", "secondID": "a b"}, "", ErrInvalidParam},
+		{"search", map[string]string{"firstID": "x", "secondID": "go"}, "", ErrInvalidParam},
+		{"search", map[string]string{"firstID": "1"}, "", ErrMissingParam},
+		{"docs", nil, "/docs", nil},
+		{"docs", map[string]string{"page": "intro"}, "/docs/intro", nil},
+		{"files", map[string]string{"path": "/css/site main.css"}, "/files/css/site%20main.css", nil},
+		{"tag", map[string]string{"tag": "a/b", "q": "x y"}, "/tags/a%2Fb?q=x+y", nil},
+		{"admin.user", map[string]string{"org": "acme", "id": "7"}, "/orgs/acme/admin/users/7", nil},
+		{"admin.user", map[string]string{"org": "ACME", "id": "7"}, "", ErrInvalidParam},
+		{"nope", nil, "", ErrUnknownRoute},
+	}
+	for _, tt := range tests {
+		got, err := rt.URLFor(tt.name, tt.params)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("URLFor(%q, %v) = %q, %v; want %q, %v", tt.name, tt.params, got, err, tt.want, tt.err)
+		}
+	}
+}
@@ -22,6 +22,9 @@ const (
 
 var ErrInvalidRequestMethod = errors.New("invalid HTTP method")
 
+// ParseMethod accepts the standard methods as well as any extension method
+// that is a valid RFC 9110 token (e.g. WebDAV's PROPFIND). Methods are
+// case-sensitive, so "get" is an extension method, not GET.
 func ParseMethod(data []byte) (Method, error) {
 	switch {
 	case bytes.Equal(data, []byte(GET)):
@@ -32,7 +35,36 @@ func ParseMethod(data []byte) (Method, error) {
 		return DELETE, nil
 	case bytes.Equal(data, []byte(PUT)):
 		return PUT, nil
+	case bytes.Equal(data, []byte(HEAD)):
+		return HEAD, nil
+	case bytes.Equal(data, []byte(OPTIONS)):
+		return OPTIONS, nil
+	case bytes.Equal(data, []byte(PATCH)):
+		return PATCH, nil
+	case bytes.Equal(data, []byte(CONNECT)):
+		return CONNECT, nil
+	case bytes.Equal(data, []byte(TRACE)):
+		return TRACE, nil
+	case isToken(data):
+		return Method(data), nil
 	default:
 		return "", fmt.Errorf("%w: %q", ErrInvalidRequestMethod, data)
 	}
 }
+
+// isToken reports whether data is a non-empty RFC 9110 token:
+// 1*( ALPHA / DIGIT / "!#$%&'*+-.^_`|~" ).
+func isToken(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	for _, c := range data {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case bytes.IndexByte([]byte("!#$%&'*+-.^_`|~"), c) != -1:
+		default:
+			return false
+		}
+	}
+	return true
+}
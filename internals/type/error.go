@@ -3,8 +3,24 @@ package types
 type RouteError struct {
 	Code    StatusCode
 	Message string
+	// Fields lists what was wrong with individual input fields, for errors
+	// raised while binding or validating a request.
+	Fields []FieldError
+	// Err is the error a handler returned, when the RouteError stands for
+	// one. Routers pick the status of mapped errors from it.
+	Err error
+}
+
+// FieldError reports one invalid input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (r *RouteError) Error() string {
 	return r.Message
 }
+
+func (r *RouteError) Unwrap() error {
+	return r.Err
+}
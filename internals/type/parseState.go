@@ -6,5 +6,7 @@ const (
 	StateRequestLine ParseState = "RequestLine"
 	StateHeader      ParseState = "Header"
 	StateBody        ParseState = "Body"
+	StateChunkedBody ParseState = "ChunkedBody"
+	StateTrailer     ParseState = "Trailer"
 	StateDone        ParseState = "Done"
 )
@@ -3,25 +3,47 @@ package types
 type StatusCode int
 
 const (
-	OK                  StatusCode = 200
-	Created             StatusCode = 201
-	NoContent           StatusCode = 204
-	BadRequest          StatusCode = 400
-	Unauthorized        StatusCode = 401
-	Forbidden           StatusCode = 403
-	NotFound            StatusCode = 404
-	MethodNotAllowed    StatusCode = 405
-	InternalServerError StatusCode = 500
+	OK                          StatusCode = 200
+	Created                     StatusCode = 201
+	NoContent                   StatusCode = 204
+	PartialContent              StatusCode = 206
+	NotModified                 StatusCode = 304
+	BadRequest                  StatusCode = 400
+	Unauthorized                StatusCode = 401
+	Forbidden                   StatusCode = 403
+	NotFound                    StatusCode = 404
+	MethodNotAllowed            StatusCode = 405
+	NotAcceptable               StatusCode = 406
+	PreconditionFailed          StatusCode = 412
+	ContentTooLarge             StatusCode = 413
+	URITooLong                  StatusCode = 414
+	UnsupportedMediaType        StatusCode = 415
+	RangeNotSatisfiable         StatusCode = 416
+	UnprocessableContent        StatusCode = 422
+	RequestHeaderFieldsTooLarge StatusCode = 431
+	InternalServerError         StatusCode = 500
+	NotImplemented              StatusCode = 501
 )
 
 var StatusText = map[StatusCode]string{
-	OK:                  "OK",
-	Created:             "Created",
-	NoContent:           "No Content",
-	BadRequest:          "Bad Request",
-	Unauthorized:        "Unauthorized",
-	Forbidden:           "Forbidden",
-	NotFound:            "Not Found",
-	MethodNotAllowed:    "Method Not Allowed",
-	InternalServerError: "Internal Server Error",
+	OK:                          "OK",
+	Created:                     "Created",
+	NoContent:                   "No Content",
+	PartialContent:              "Partial Content",
+	NotModified:                 "Not Modified",
+	BadRequest:                  "Bad Request",
+	Unauthorized:                "Unauthorized",
+	Forbidden:                   "Forbidden",
+	NotFound:                    "Not Found",
+	MethodNotAllowed:            "Method Not Allowed",
+	NotAcceptable:               "Not Acceptable",
+	PreconditionFailed:          "Precondition Failed",
+	ContentTooLarge:             "Content Too Large",
+	URITooLong:                  "URI Too Long",
+	UnsupportedMediaType:        "Unsupported Media Type",
+	RangeNotSatisfiable:         "Range Not Satisfiable",
+	UnprocessableContent:        "Unprocessable Content",
+	RequestHeaderFieldsTooLarge: "Request Header Fields Too Large",
+	InternalServerError:         "Internal Server Error",
+	NotImplemented:              "Not Implemented",
 }
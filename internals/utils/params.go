@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	types "myserver/internals/type"
+)
+
+// IsCatchAll reports whether segment captures the rest of the path, written
+// either "{name...}" or "*name".
+func IsCatchAll(segment string) bool {
+	if strings.HasPrefix(segment, "*") {
+		return true
+	}
+	return IsParam(segment) && strings.HasSuffix(segment, "...}")
+}
+
+// ParseParam splits a parameter segment into its parts:
+//
+//	{name}             plain parameter
+//	{name:constraint}  parameter checked against a constraint
+//	{name?}            optional parameter (also {name?:constraint})
+//	{name...} / *name  catch-all, captures the rest of the path
+func ParseParam(segment string) (name, constraint string, optional bool) {
+	if strings.HasPrefix(segment, "*") {
+		return segment[1:], "", false
+	}
+	inner := segment[1 : len(segment)-1]
+	if rest, ok := strings.CutSuffix(inner, "..."); ok {
+		return rest, "", false
+	}
+	name = inner
+	if idx := strings.IndexByte(inner, ':'); idx != -1 {
+		name, constraint = inner[:idx], inner[idx+1:]
+	}
+	if trimmed, ok := strings.CutSuffix(name, "?"); ok {
+		return trimmed, constraint, true
+	}
+	return name, constraint, false
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Constraint compiles the constraint part of a route parameter into a
+// matcher. "int", "uuid" and "bool" are built in; anything else is a regular
+// expression that must match the whole segment, e.g. "[a-z-]+".
+func Constraint(expr string) (func(string) bool, error) {
+	switch expr {
+	case "":
+		return nil, nil
+	case "int":
+		return func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		}, nil
+	case "uuid":
+		return uuidPattern.MatchString, nil
+	case "bool":
+		return func(s string) bool {
+			_, err := strconv.ParseBool(s)
+			return err == nil
+		}, nil
+	}
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// paramError is the 400 returned when a parameter cannot be converted.
+func paramError(key, value, kind string) *types.RouteError {
+	return &types.RouteError{
+		Code:    types.BadRequest,
+		Message: fmt.Sprintf("parameter %q: %q is not a valid %s", key, value, kind),
+	}
+}
+
+// lookup is Get with a RouteError, so typed accessors can be returned from a
+// handler as-is.
+func (p *Params) lookup(key string) (string, *types.RouteError) {
+	value, err := p.Get(key)
+	if err != nil {
+		return "", &types.RouteError{Code: types.BadRequest, Message: err.Error()}
+	}
+	return value, nil
+}
+
+// Int returns the parameter as an int, or a 400 RouteError.
+func (p *Params) Int(key string) (int, *types.RouteError) {
+	value, routeErr := p.lookup(key)
+	if routeErr != nil {
+		return 0, routeErr
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, paramError(key, value, "integer")
+	}
+	return n, nil
+}
+
+// Int64 returns the parameter as an int64, or a 400 RouteError.
+func (p *Params) Int64(key string) (int64, *types.RouteError) {
+	value, routeErr := p.lookup(key)
+	if routeErr != nil {
+		return 0, routeErr
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, paramError(key, value, "integer")
+	}
+	return n, nil
+}
+
+// UUID returns the parameter lower-cased after checking it is in the
+// 8-4-4-4-12 hex form, or a 400 RouteError.
+func (p *Params) UUID(key string) (string, *types.RouteError) {
+	value, routeErr := p.lookup(key)
+	if routeErr != nil {
+		return "", routeErr
+	}
+	if !uuidPattern.MatchString(value) {
+		return "", paramError(key, value, "UUID")
+	}
+	return strings.ToLower(value), nil
+}
+
+// Bool returns the parameter as a bool (1, t, true, 0, f, false, ...), or a
+// 400 RouteError.
+func (p *Params) Bool(key string) (bool, *types.RouteError) {
+	value, routeErr := p.lookup(key)
+	if routeErr != nil {
+		return false, routeErr
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, paramError(key, value, "boolean")
+	}
+	return b, nil
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	types "myserver/internals/type"
+)
+
+func TestParamsAccessors(t *testing.T) {
+	params := Params{
+		{Key: "id", Value: "42"},
+		{Key: "neg", Value: "-7"},
+		{Key: "big", Value: "9223372036854775807"},
+		{Key: "huge", Value: "9223372036854775808"},
+		{Key: "word", Value: "abc"},
+		{Key: "float", Value: "1.5"},
+		{Key: "empty", Value: ""},
+		{Key: "uuid", Value: "123E4567-E89B-12D3-A456-426614174000"},
+		{Key: "short", Value: "123e4567-e89b-12d3-a456-42661417400"},
+		{Key: "flag", Value: "true"},
+		{Key: "off", Value: "0"},
+		{Key: "yes", Value: "yes"},
+	}
+
+	tests := []struct {
+		name string
+		get  func(p *Params) (any, *types.RouteError)
+		want any
+		// a substring of the 400 message; empty means no error
+		err string
+	}{
+		{"Int", func(p *Params) (any, *types.RouteError) { return p.Int("id") }, 42, ""},
+		{"Int negative", func(p *Params) (any, *types.RouteError) { return p.Int("neg") }, -7, ""},
+		{"Int not a number", func(p *Params) (any, *types.RouteError) { return p.Int("word") }, 0, `"abc" is not a valid integer`},
+		{"Int float", func(p *Params) (any, *types.RouteError) { return p.Int("float") }, 0, "not a valid integer"},
+		{"Int empty", func(p *Params) (any, *types.RouteError) { return p.Int("empty") }, 0, "not a valid integer"},
+		{"Int missing", func(p *Params) (any, *types.RouteError) { return p.Int("nope") }, 0, "parameter not found: nope"},
+		{"Int64", func(p *Params) (any, *types.RouteError) { return p.Int64("big") }, int64(9223372036854775807), ""},
+		{"Int64 overflow", func(p *Params) (any, *types.RouteError) { return p.Int64("huge") }, int64(0), `parameter "huge"`},
+		{"Int64 missing", func(p *Params) (any, *types.RouteError) { return p.Int64("nope") }, int64(0), "parameter not found"},
+		{"UUID is lower-cased", func(p *Params) (any, *types.RouteError) { return p.UUID("uuid") }, "123e4567-e89b-12d3-a456-426614174000", ""},
+		{"UUID too short", func(p *Params) (any, *types.RouteError) { return p.UUID("short") }, "", "not a valid UUID"},
+		{"UUID not hex", func(p *Params) (any, *types.RouteError) { return p.UUID("word") }, "", "not a valid UUID"},
+		{"UUID missing", func(p *Params) (any, *types.RouteError) { return p.UUID("nope") }, "", "parameter not found"},
+		{"Bool true", func(p *Params) (any, *types.RouteError) { return p.Bool("flag") }, true, ""},
+		{"Bool 0", func(p *Params) (any, *types.RouteError) { return p.Bool("off") }, false, ""},
+		{"Bool yes", func(p *Params) (any, *types.RouteError) { return p.Bool("yes") }, false, `"yes" is not a valid boolean`},
+		{"Bool missing", func(p *Params) (any, *types.RouteError) { return p.Bool("nope") }, false, "parameter not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, routeErr := tt.get(&params)
+			if got != tt.want {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+			if tt.err == "" {
+				if routeErr != nil {
+					t.Errorf("unexpected error %q", routeErr.Message)
+				}
+				return
+			}
+			if routeErr == nil {
+				t.Fatalf("no error, want a 400 containing %q", tt.err)
+			}
+			if routeErr.Code != types.BadRequest || !strings.Contains(routeErr.Message, tt.err) {
+				t.Errorf("error = %d %q, want 400 containing %q", routeErr.Code, routeErr.Message, tt.err)
+			}
+		})
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		expr  string
+		value string
+		want  bool
+	}{
+		{"int", "123", true},
+		{"int", "-1", true},
+		{"int", "1a", false},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123e4567", false},
+		{"bool", "false", true},
+		{"bool", "no", false},
+		// expressions must match the whole segment
+		{"[a-z]+", "abc", true},
+		{"[a-z]+", "abc1", false},
+		{"a|b", "ab", false},
+		{"a|b", "b", true},
+	}
+	for _, tt := range tests {
+		match, err := Constraint(tt.expr)
+		if err != nil {
+			t.Fatalf("Constraint(%q): %v", tt.expr, err)
+		}
+		if got := match(tt.value); got != tt.want {
+			t.Errorf("Constraint(%q)(%q) = %v, want %v", tt.expr, tt.value, got, tt.want)
+		}
+	}
+
+	if match, err := Constraint(""); match != nil || err != nil {
+		t.Errorf(`Constraint("") = %v, %v; want no matcher`, match != nil, err)
+	}
+	if _, err := Constraint("[a-"); err == nil {
+		t.Error(`Constraint("[a-") compiled`)
+	}
+}
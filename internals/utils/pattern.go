@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidPattern = errors.New("invalid route pattern")
+
+// ValidatePattern checks the syntax of a route pattern: it must start with
+// '/', have no empty segments, and every parameter must be a whole segment
+// with a unique, non-empty name made of letters, digits and '_'. A catch-all
+// may only be the last segment and constraints must compile. Since a route
+// may stop before any optional parameter, only optional parameters may
+// follow one.
+func ValidatePattern(pattern string) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("%w %q: must start with '/'", ErrInvalidPattern, pattern)
+	}
+	trimmed := strings.TrimSuffix(pattern[1:], "/")
+	if trimmed == "" {
+		return nil
+	}
+	segments := strings.Split(trimmed, "/")
+	seen := make(map[string]bool)
+	optional := "" // the first optional parameter
+	for i, seg := range segments {
+		if optional != "" && !isOptional(seg) {
+			return fmt.Errorf("%w %q: %q follows optional parameter %q", ErrInvalidPattern, pattern, seg, optional)
+		}
+		switch {
+		case seg == "":
+			return fmt.Errorf("%w %q: empty segment", ErrInvalidPattern, pattern)
+		case IsCatchAll(seg):
+			if i != len(segments)-1 {
+				return fmt.Errorf("%w %q: catch-all %q must be the last segment", ErrInvalidPattern, pattern, seg)
+			}
+		case IsParam(seg):
+		default:
+			if strings.ContainsAny(seg, "{}") {
+				return fmt.Errorf("%w %q: parameter %q must span a whole segment", ErrInvalidPattern, pattern, seg)
+			}
+			continue
+		}
+
+		name, constraint, opt := ParseParam(seg)
+		if opt && optional == "" {
+			optional = seg
+		}
+		if !isParamName(name) {
+			return fmt.Errorf("%w %q: bad parameter name %q", ErrInvalidPattern, pattern, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w %q: duplicate parameter %q", ErrInvalidPattern, pattern, name)
+		}
+		seen[name] = true
+		if _, err := Constraint(constraint); err != nil {
+			return fmt.Errorf("%w %q: constraint of %q: %v", ErrInvalidPattern, pattern, name, err)
+		}
+	}
+	return nil
+}
+
+func isOptional(segment string) bool {
+	if !IsParam(segment) || IsCatchAll(segment) {
+		return false
+	}
+	_, _, optional := ParseParam(segment)
+	return optional
+}
+
+func isParamName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
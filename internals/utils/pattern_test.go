@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		// a substring of the error; empty means the pattern is valid
+		err string
+	}{
+		{"/", ""},
+		{"/users/", ""},
+		{"/users/{id}", ""},
+		{"/users/{id:int}/posts/{slug:[a-z-]+}", ""},
+		{"/files/{path...}", ""},
+		{"/files/*path", ""},
+		{"/docs/{page?}", ""},
+		{"/d/{a?}/{b?:int}", ""},
+		{"users", "must start with '/'"},
+		{"/a//b", "empty segment"},
+		{"/files/{path...}/edit", "must be the last segment"},
+		{"/users/id{id}", "must span a whole segment"},
+		{"/users/{id", "must span a whole segment"},
+		{"/users/{}", "bad parameter name"},
+		{"/users/{user-id}", "bad parameter name"},
+		{"/a/{id}/b/{id}", `duplicate parameter "id"`},
+		{"/a/{id:[a-}", "constraint of"},
+		// a route may stop before an optional parameter, so nothing
+		// required can come after one
+		{"/a/{b?}/c", `"c" follows optional parameter "{b?}"`},
+		{"/a/{b?}/{c}", `"{c}" follows optional parameter "{b?}"`},
+		{"/a/{b?}/{c?}/d", `"d" follows optional parameter "{b?}"`},
+		{"/a/{b?}/{rest...}", "follows optional parameter"},
+	}
+	for _, tt := range tests {
+		err := ValidatePattern(tt.pattern)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("ValidatePattern(%q) = %v", tt.pattern, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidPattern) || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ValidatePattern(%q) = %v, want an error containing %q", tt.pattern, err, tt.err)
+		}
+	}
+}
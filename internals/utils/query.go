@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query maps query keys to their values, in the order they were sent.
+type Query map[string][]string
+
+// ParseQuery decodes a query string such as "a=1&b=x+y&a=2". Pairs with a
+// broken escape are skipped rather than failing the whole query.
+func ParseQuery(raw string) Query {
+	q := make(Query)
+	for pair := range strings.SplitSeq(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := Unescape(key, true)
+		if err != nil {
+			continue
+		}
+		value, err = Unescape(value, true)
+		if err != nil {
+			continue
+		}
+		q[key] = append(q[key], value)
+	}
+	return q
+}
+
+// Get returns the first value of key, or "".
+func (q Query) Get(key string) string {
+	if values := q[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Values returns every value of key.
+func (q Query) Values(key string) []string {
+	return q[key]
+}
+
+// Has reports whether key was sent, even without a value.
+func (q Query) Has(key string) bool {
+	_, ok := q[key]
+	return ok
+}
+
+// String returns the first value of key, or def when key is missing.
+func (q Query) String(key, def string) string {
+	if !q.Has(key) {
+		return def
+	}
+	return q.Get(key)
+}
+
+// Int returns the first value of key as an int, or def when key is missing
+// or not a number.
+func (q Query) Int(key string, def int) int {
+	n, err := strconv.Atoi(q.Get(key))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Int64 is like Int for int64 values.
+func (q Query) Int64(key string, def int64) int64 {
+	n, err := strconv.ParseInt(q.Get(key), 10, 64)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Float64 is like Int for floating-point values.
+func (q Query) Float64(key string, def float64) float64 {
+	f, err := strconv.ParseFloat(q.Get(key), 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// Bool returns the first value of key as a bool ("1", "true", "0", "false",
+// ...), or def when key is missing or not a boolean.
+func (q Query) Bool(key string, def bool) bool {
+	b, err := strconv.ParseBool(q.Get(key))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// Unescape decodes "%XX" escapes in s. With plusSpace, as in a query
+// string, '+' decodes to a space.
+func Unescape(s string, plusSpace bool) (string, error) {
+	if !strings.ContainsAny(s, "%+") {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return "", fmt.Errorf("%w: %q", ErrInvalidEscape, s)
+			}
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		case c == '+' && plusSpace:
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	default:
+		return c - 'a' + 10
+	}
+}
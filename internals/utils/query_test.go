@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		s         string
+		plusSpace bool
+		want      string
+		err       error
+	}{
+		{s: "plain", want: "plain"},
+		{s: "a%20b", want: "a b"},
+		{s: "%41%62%2f", want: "Ab/"},
+		{s: "caf%C3%A9", want: "café"},
+		{s: "a+b", want: "a+b"},
+		{s: "a+b", plusSpace: true, want: "a b"},
+		{s: "a%2Bb", plusSpace: true, want: "a+b"},
+		{s: "%25", want: "%"},
+		{s: "", want: ""},
+		{s: "%", err: ErrInvalidEscape},
+		{s: "a%4", err: ErrInvalidEscape},
+		{s: "a%zz", err: ErrInvalidEscape},
+		{s: "a%g1b", err: ErrInvalidEscape},
+		{s: "100%", plusSpace: true, err: ErrInvalidEscape},
+	}
+	for _, tt := range tests {
+		got, err := Unescape(tt.s, tt.plusSpace)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("Unescape(%q, %v) = %q, %v; want %q, %v", tt.s, tt.plusSpace, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Query
+	}{
+		{"", Query{}},
+		{"a=1", Query{"a": {"1"}}},
+		{"a=1&b=x+y&a=2", Query{"a": {"1", "2"}, "b": {"x y"}}},
+		{"flag&empty=", Query{"flag": {""}, "empty": {""}}},
+		{"&&a=1&", Query{"a": {"1"}}},
+		{"a=b=c", Query{"a": {"b=c"}}},
+		{"na%6De=caf%C3%A9", Query{"name": {"café"}}},
+		{"q=a%26b&r=%3D", Query{"q": {"a&b"}, "r": {"="}}},
+		// pairs with a broken escape are skipped, the rest is kept
+		{"a=%zz&b=2", Query{"b": {"2"}}},
+		{"bad%=1&b=2", Query{"b": {"2"}}},
+		{"a=1&a=%", Query{"a": {"1"}}},
+	}
+	for _, tt := range tests {
+		if got := ParseQuery(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQuery(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestQueryGetters(t *testing.T) {
+	q := ParseQuery("page=3&big=9000000000&ratio=0.5&on=true&off=0&word=abc&empty=&page=4")
+
+	if got := q.Get("page"); got != "3" {
+		t.Errorf("Get(page) = %q, want the first value", got)
+	}
+	if got := q.Values("page"); !reflect.DeepEqual(got, []string{"3", "4"}) {
+		t.Errorf("Values(page) = %q", got)
+	}
+	if !q.Has("empty") || q.Has("missing") {
+		t.Errorf("Has: empty = %v, missing = %v", q.Has("empty"), q.Has("missing"))
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"String", q.String("word", "def"), "abc"},
+		{"String empty value", q.String("empty", "def"), ""},
+		{"String missing", q.String("missing", "def"), "def"},
+		{"Int", q.Int("page", 1), 3},
+		{"Int not a number", q.Int("word", 1), 1},
+		{"Int empty", q.Int("empty", 1), 1},
+		{"Int missing", q.Int("missing", 1), 1},
+		{"Int64", q.Int64("big", 1), int64(9000000000)},
+		{"Int64 not a number", q.Int64("ratio", 1), int64(1)},
+		{"Float64", q.Float64("ratio", 1), 0.5},
+		{"Float64 missing", q.Float64("missing", 1.5), 1.5},
+		{"Bool true", q.Bool("on", false), true},
+		{"Bool 0", q.Bool("off", true), false},
+		{"Bool not a boolean", q.Bool("word", true), true},
+		{"Bool missing", q.Bool("missing", true), true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidTarget = fmt.Errorf("invalid request target")
+	ErrInvalidEscape = fmt.Errorf("invalid percent-encoding")
+)
+
+// TargetForm is one of the four request-target forms of RFC 9112 3.2.
+type TargetForm int
+
+const (
+	OriginForm    TargetForm = iota // "/path?query", the usual form
+	AbsoluteForm                    // "http://host/path?query", sent to proxies
+	AuthorityForm                   // "host:port", only for CONNECT
+	AsteriskForm                    // "*", only for OPTIONS
+)
+
+// Target is a parsed request target.
+type Target struct {
+	Form TargetForm
+	// Scheme is the lower-cased scheme of an absolute-form target.
+	Scheme string
+	// Host is the authority of an absolute-form or authority-form target,
+	// port included.
+	Host string
+	URL  URL
+}
+
+// URL is the path and query of a request target.
+type URL struct {
+	// Path is the percent-decoded path with dot segments removed, the one
+	// routes are matched on.
+	Path string
+	// RawPath is the same path with its escapes kept, so "%2F" can still be
+	// told apart from "/".
+	RawPath string
+	// RawQuery is the query string as sent, without the '?'.
+	RawQuery string
+	query    Query
+}
+
+// Query returns the parsed query string. It is parsed on first use and
+// shared afterwards, so callers must not modify it.
+func (u *URL) Query() Query {
+	if u.query == nil {
+		u.query = ParseQuery(u.RawQuery)
+	}
+	return u.query
+}
+
+// ParseTarget parses the target of a request line (RFC 3986, RFC 9112 3.2).
+// The authority-form is only accepted for CONNECT, which must use it.
+func ParseTarget(target string, connect bool) (Target, error) {
+	switch {
+	case connect:
+		if err := checkAuthority(target, true); err != nil {
+			return Target{}, err
+		}
+		return Target{Form: AuthorityForm, Host: target}, nil
+	case target == "*":
+		return Target{Form: AsteriskForm, URL: URL{Path: "*", RawPath: "*"}}, nil
+	case strings.HasPrefix(target, "/"):
+		u, err := ParseURL(target)
+		return Target{Form: OriginForm, URL: u}, err
+	}
+
+	// absolute-form: scheme "://" authority path-abempty [ "?" query ]
+	scheme, rest, ok := strings.Cut(target, "://")
+	if !ok || !validScheme(scheme) {
+		return Target{}, fmt.Errorf("%w: %q", ErrInvalidTarget, target)
+	}
+	end := strings.IndexAny(rest, "/?")
+	if end == -1 {
+		end = len(rest)
+	}
+	host, path := rest[:end], rest[end:]
+	if err := checkAuthority(host, false); err != nil {
+		return Target{}, err
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u, err := ParseURL(path)
+	if err != nil {
+		return Target{}, err
+	}
+	return Target{Form: AbsoluteForm, Scheme: strings.ToLower(scheme), Host: host, URL: u}, nil
+}
+
+// ParseURL parses an origin-form target: the path is checked, cleaned of
+// "." and ".." segments, then percent-decoded; the query is kept raw until
+// Query is called. "+" only means a space in the query, so it is kept as is
+// in the path. The decoded path is cleaned again, so an escaped slash
+// ("%2F..%2F") cannot smuggle a ".." segment in.
+func ParseURL(target string) (URL, error) {
+	rawPath, rawQuery, _ := strings.Cut(target, "?")
+	if !strings.HasPrefix(rawPath, "/") || !validTargetChars(target) {
+		return URL{}, fmt.Errorf("%w: %q", ErrInvalidTarget, target)
+	}
+	rawPath = RemoveDotSegments(rawPath)
+	path, err := Unescape(rawPath, false)
+	if err != nil {
+		return URL{}, err
+	}
+	path = RemoveDotSegments(path)
+	return URL{Path: path, RawPath: rawPath, RawQuery: rawQuery}, nil
+}
+
+// RemoveDotSegments resolves "." and ".." segments in an absolute path (RFC
+// 3986 5.2.4), never climbing above the root: "/a/./b/../c" is "/a/c" and
+// "/../x" is "/x". Escaped dots ("%2e") count as dots.
+func RemoveDotSegments(path string) string {
+	if !strings.Contains(path, ".") && !strings.Contains(path, "%2") {
+		return path
+	}
+	segments := strings.Split(path[1:], "/")
+	out := make([]string, 0, len(segments))
+	// a trailing dot segment leaves its directory with a trailing slash
+	trailing := false
+	for _, seg := range segments {
+		trailing = false
+		switch dotSegment(seg) {
+		case 1:
+			trailing = true
+		case 2:
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+			trailing = true
+		default:
+			out = append(out, seg)
+		}
+	}
+	cleaned := "/" + strings.Join(out, "/")
+	if trailing && len(out) > 0 {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// dotSegment returns 1 for a "." segment, 2 for ".." and 0 otherwise.
+func dotSegment(seg string) int {
+	if len(seg) == 0 || len(seg) > 6 {
+		return 0
+	}
+	switch strings.ReplaceAll(strings.ToLower(seg), "%2e", ".") {
+	case ".":
+		return 1
+	case "..":
+		return 2
+	default:
+		return 0
+	}
+}
+
+// validTargetChars rejects what can never appear in a request target:
+// control characters, spaces and a fragment. Other bytes outside the RFC
+// 3986 set, such as raw UTF-8, are tolerated since clients do send them.
+func validTargetChars(target string) bool {
+	for i := 0; i < len(target); i++ {
+		if c := target[i]; c <= ' ' || c == 0x7f || c == '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// validScheme checks scheme = ALPHA *( ALPHA / DIGIT / "+" / "-" / "." ).
+func validScheme(scheme string) bool {
+	if scheme == "" || !isAlpha(scheme[0]) {
+		return false
+	}
+	for i := 1; i < len(scheme); i++ {
+		c := scheme[i]
+		if !isAlpha(c) && !('0' <= c && c <= '9') && c != '+' && c != '-' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAuthority validates host [ ":" port ]. HTTP has no use for userinfo,
+// so "user@host" is rejected (RFC 9110 4.2.4).
+func checkAuthority(authority string, requirePort bool) error {
+	invalid := fmt.Errorf("%w: invalid authority %q", ErrInvalidTarget, authority)
+	host, port := authority, ""
+	if strings.HasPrefix(authority, "[") {
+		end := strings.IndexByte(authority, ']')
+		if end == -1 {
+			return invalid
+		}
+		host, port = authority[1:end], authority[end+1:]
+		if port != "" && port[0] != ':' {
+			return invalid
+		}
+		port = strings.TrimPrefix(port, ":")
+		for i := 0; i < len(host); i++ {
+			if c := host[i]; !isHex(c) && c != ':' && c != '.' {
+				return invalid
+			}
+		}
+	} else {
+		if i := strings.LastIndexByte(authority, ':'); i != -1 {
+			host, port = authority[:i], authority[i+1:]
+		}
+		for i := 0; i < len(host); i++ {
+			if c := host[i]; !isUnreserved(c) && c != '%' && strings.IndexByte("!$&'()*+,;=", c) == -1 {
+				return invalid
+			}
+		}
+	}
+	if host == "" || (requirePort && port == "") {
+		return invalid
+	}
+	for i := 0; i < len(port); i++ {
+		if port[i] < '0' || port[i] > '9' {
+			return invalid
+		}
+	}
+	return nil
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isUnreserved(c byte) bool {
+	return isAlpha(c) || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
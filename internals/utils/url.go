@@ -2,8 +2,6 @@ package utils
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
 )
 
 var (
@@ -11,34 +9,25 @@ var (
 	ErrParamNotFound = fmt.Errorf("parameter not found")
 )
 
-type Params map[string]string
+// Param is a single captured route parameter.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params holds the route parameters in the order they appear in the pattern.
+// It is a slice rather than a map so the router can fill a reused buffer.
+type Params []Param
 
 func IsParam(segment string) bool {
 	return len(segment) >= 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
 }
 
-// clean path from ? = &.
-func CleanURL(url *string) string {
-	if *url == "" {
-		return ""
-	}
-
-	// Find the index of '?' which starts the query parameters
-	if idx := strings.Index(*url, "?"); idx != -1 {
-		*url = (*url)[:idx] // keep only the part before '?'
-	}
-	// Basic validation: must start with '/' and contain only allowed chars
-	validPath := regexp.MustCompile(`^\/[a-zA-Z0-9\/\-\_\.]*$`)
-	if !validPath.MatchString(*url) {
-		return ""
-	}
-
-	return *url
-}
 func (p *Params) Get(key string) (string, error) {
-	value, ok := (*p)[key]
-	if !ok {
-		return "", fmt.Errorf("%w: %s", ErrParamNotFound, key)
+	for _, param := range *p {
+		if param.Key == key {
+			return param.Value, nil
+		}
 	}
-	return value, nil
+	return "", fmt.Errorf("%w: %s", ErrParamNotFound, key)
 }
@@ -0,0 +1,8 @@
+// Package static holds the site's assets. They are embedded so the server
+// binary can be deployed on its own.
+package static
+
+import "embed"
+
+//go:embed *.html *.css *.js *.jpg
+var Files embed.FS